@@ -2,28 +2,24 @@
 package bloomFilter
 
 import (
-	"crypto/md5"
-	"crypto/sha1"
-	"crypto/sha256"
-	"crypto/sha512"
-	"encoding/binary"
-	"hash"
 	"math"
-	"math/rand"
-	"strconv"
+	"math/bits"
 	"sync"
 )
 
 type bloom struct {
-	bitNum   uint64
-	hashFunc []func(val []byte) uint64 // 哈希函数数组
-	bit      []uint64                  // bloom位数组
-	lock     sync.RWMutex              // 读写锁
-	num      int                       // 布隆中元素个数
+	bitNum uint64
+	hasher hasher       // 计算数据在位数组中的位置
+	bit    []uint64     // bloom位数组
+	lock   sync.RWMutex // 读写锁
+	num    int          // 布隆中元素个数(重复数据不计数)
+	ones   uint64       // 位数组中置1的位数量
 }
 
-var hashBaseFunc = []hash.Hash{md5.New(), sha1.New(), sha256.New()}
-var minHashNum = uint64(len(hashBaseFunc))
+// DefaultSeed NewBloom 使用的哈希种子
+const DefaultSeed uint32 = 0x9747b28c
+
+var minHashNum = uint64(1)
 var maxHashNum = uint64(15)
 
 /*
@@ -32,49 +28,42 @@ param maxN 预计元素数量
 param p 期望误差概率
 */
 func NewBloom(maxN uint64, p float64) *bloom {
-	var (
-		bitNum  = optimalM(maxN, p)
-		hashNum = optimalK(bitNum, maxN)
-		bloom   = &bloom{
-			bitNum:   bitNum,
-			hashFunc: make([]func(val []byte) uint64, 0),
-			bit:      make([]uint64, (bitNum+63)/64),
-			lock:     sync.RWMutex{},
-			num:      0,
-		}
-	)
+	return NewBloomWithSeed(maxN, p, DefaultSeed)
+}
+
+// NewBloomWithSeed 以指定的哈希种子初始化过滤器，参数与种子相同的过滤器在任何进程中都是兼容的
+func NewBloomWithSeed(maxN uint64, p float64, seed uint32) *bloom {
+	bitNum := optimalM(maxN, p)
+	hashNum := optimalK(bitNum, maxN)
 	if hashNum < minHashNum {
 		hashNum = minHashNum
 	}
 	if hashNum > maxHashNum {
 		hashNum = maxHashNum
 	}
-	bloom.baseHash()
-	for _, salt := range createSalt(hashNum - minHashNum) {
-		bloom.hashFunc = append(bloom.hashFunc, bloom.createHash(salt))
-	}
-	return bloom
-}
-
-// 生成hash函数createHash
-func (b *bloom) createHash(salt []byte) func(val []byte) uint64 {
-	return func(val []byte) uint64 {
-		hs := sha512.New()
-		res := hs.Sum(append(val, salt...))
-		return binary.BigEndian.Uint64(res[:8]) % b.bitNum
+	return &bloom{
+		bitNum: bitNum,
+		hasher: &doubleHasher{hashNum: hashNum, bitNum: bitNum, seed: seed},
+		bit:    make([]uint64, (bitNum+63)/64),
+		lock:   sync.RWMutex{},
+		num:    0,
 	}
 }
 
 // Check 判断数据是否在布隆过滤器中(存在true 不存在false)
 func (b *bloom) Check(val []byte) bool {
-	for _, hs := range b.hashFunc {
-		hsVal := hs(val)
-		k := hsVal / 64  // 对应数组下标在哪
-		mk := hsVal % 64 // 在对应数组下标的第几个偏移量中
-		b.lock.RLock()
-		var t = b.bit[k] & (1 << mk)
-		b.lock.RUnlock()
-		if t == 0 { // 找到bitmap中对应下标，对应偏移量的位，判断是否0
+	var buf [16]uint64
+	locations := b.hasher.locations(val, buf[:0])
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return checkBits(b.bit, locations)
+}
+
+// checkBits 判断各个位是否都为1
+func checkBits(bit []uint64, locations []uint64) bool {
+	for _, hsVal := range locations {
+		// 找到bitmap中对应下标(hsVal/64)，对应偏移量(hsVal%64)的位，判断是否0
+		if bit[hsVal/64]&(1<<(hsVal%64)) == 0 {
 			return false
 		}
 	}
@@ -83,26 +72,50 @@ func (b *bloom) Check(val []byte) bool {
 
 // Add 数据插入布隆过滤器(不可删除) error is always return nil
 func (b *bloom) Add(val []byte) error {
-	for _, hs := range b.hashFunc {
-		hsVal := hs(val)
-		k := hsVal / 64  // 对应数组下标在哪
-		mk := hsVal % 64 // 在对应数组下标的第几个偏移量中
-		b.lock.Lock()
-		b.bit[k] = b.bit[k] | (1 << mk)
-		b.lock.Unlock()
-	}
+	var buf [16]uint64
+	locations := b.hasher.locations(val, buf[:0])
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.add(locations)
 	return nil
 }
 
-// 基础的hash函数(不加盐)
-func (b *bloom) baseHash() {
-	for _, hs := range hashBaseFunc {
-		var f = func(val []byte) uint64 {
-			res := hs.Sum(val)
-			return binary.BigEndian.Uint64(res[:8]) % b.bitNum
+// add 将各个位置1，返回是否有新的位被置1。没有新的位时数据(可能)已存在，不计入元素个数
+func (b *bloom) add(locations []uint64) bool {
+	added := false
+	for _, hsVal := range locations {
+		mask := uint64(1) << (hsVal % 64)
+		if b.bit[hsVal/64]&mask == 0 {
+			b.bit[hsVal/64] |= mask
+			b.ones++
+			added = true
 		}
-		b.hashFunc = append(b.hashFunc, f)
 	}
+	if added {
+		b.num++
+	}
+	return added
+}
+
+// Len 布隆中元素个数。判断为已存在的数据不计数，因此可能略少于实际插入的不同数据个数
+func (b *bloom) Len() int {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return b.num
+}
+
+// fillRatio 位数组中置1的位所占比例
+func (b *bloom) fillRatio() float64 {
+	return float64(b.ones) / float64(b.bitNum)
+}
+
+// popCount 统计位数组中置1的位数量
+func popCount(bit []uint64) uint64 {
+	var n int
+	for _, v := range bit {
+		n += bits.OnesCount64(v)
+	}
+	return uint64(n)
 }
 
 // 计算过滤器-所需的位数量
@@ -114,12 +127,3 @@ func optimalM(maxN uint64, p float64) uint64 {
 func optimalK(m, maxN uint64) uint64 {
 	return uint64(math.Ceil(float64(m) * math.Ln2 / float64(maxN)))
 }
-
-// 生成指定位数的salt
-func createSalt(num uint64) [][]byte {
-	var res = make([][]byte, 0)
-	for i := uint64(0); i < num; i++ {
-		res = append(res, []byte(string(rand.Int31())+strconv.FormatUint(i, 10)))
-	}
-	return res
-}
@@ -3,6 +3,7 @@ package bloomFilter
 import (
 	"fmt"
 	"math/rand"
+	"slices"
 	"strconv"
 	"testing"
 )
@@ -15,7 +16,7 @@ func init() {
 		testTexts = append(testTexts, []byte(string(rand.Int31())+strconv.Itoa(i)))
 	}
 	println(bloomTest.bitNum, "位数量")
-	println(len(bloomTest.hashFunc), "哈希函数数量")
+	println(bloomTest.hasher.count(), "哈希函数数量")
 }
 
 // 1.3M内存 0.83S
@@ -73,3 +74,64 @@ func Benchmark_Add(b *testing.B) {
 // 		fmt.Println("success")
 // 	}
 // }
+
+// 参数与种子相同的过滤器在不同进程中位数组完全一致
+func Test_BloomDeterministic(t *testing.T) {
+	a, b := NewBloom(1000, 0.01), NewBloom(1000, 0.01)
+	other := NewBloomWithSeed(1000, 0.01, 1)
+	for i := 0; i < 500; i++ {
+		key := []byte("key" + strconv.Itoa(i))
+		_ = a.Add(key)
+		_ = b.Add(key)
+		_ = other.Add(key)
+	}
+	if !slices.Equal(a.bit, b.bit) {
+		t.Fatal("expect identical bits for the same seed")
+	}
+	if slices.Equal(a.bit, other.bit) {
+		t.Fatal("expect different bits for different seeds")
+	}
+	// 误判率应接近期望值
+	falsePositive := 0
+	for i := 0; i < 10000; i++ {
+		if a.Check([]byte("miss" + strconv.Itoa(i))) {
+			falsePositive++
+		}
+	}
+	if rate := float64(falsePositive) / 10000; rate > 0.01 {
+		t.Fatalf("false positive rate %f too high", rate)
+	}
+}
+
+// 旧版哈希与murmur3双重哈希的吞吐对比
+func Benchmark_Hasher(b *testing.B) {
+	for _, c := range []struct {
+		name  string
+		bloom *bloom
+	}{
+		{"legacy", newLegacyBloom(10000000, 0.001)},
+		{"murmur3", NewBloom(10000000, 0.001)},
+	} {
+		b.Run(c.name+"/Add", func(b *testing.B) {
+			for j := 0; j < b.N; j++ {
+				_ = c.bloom.Add(testTexts[j%len(testTexts)])
+			}
+		})
+		b.Run(c.name+"/Check", func(b *testing.B) {
+			for j := 0; j < b.N; j++ {
+				c.bloom.Check(testTexts[j%len(testTexts)])
+			}
+		})
+	}
+}
+
+func Test_DoubleHasherDistinct(t *testing.T) {
+	h := &doubleHasher{hashNum: 7, bitNum: 9586, seed: DefaultSeed}
+	var buf [16]uint64
+	for i := 0; i < 200000; i++ {
+		locations := h.locations([]byte("key"+strconv.Itoa(i)), buf[:0])
+		if locations[0] == locations[1] {
+			t.Fatalf("key%d: all locations collapse to bit %d", i, locations[0])
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package bloomFilter
+
+import (
+	"errors"
+	"sync"
+)
+
+const (
+	counterBits = 4                  // 每个计数器的位数
+	counterMax  = 1<<counterBits - 1 // 计数器上限，达到后不再增减(饱和)
+	counterPer  = 64 / counterBits   // 每个uint64容纳的计数器数量
+	counterMask = uint64(counterMax) // 单个计数器的掩码
+)
+
+var NotFoundError = errors.New("bloom: value not in filter") // 删除不存在的数据
+
+// countingBloom 计数布隆过滤器，每个槽位是一个4位的饱和计数器，支持删除
+//
+// 内存占用是普通布隆过滤器的4倍。计数器达到15后不再增减，该槽位此后不会被删除清零，以避免误删其他数据
+type countingBloom struct {
+	slotNum uint64
+	hasher  hasher
+	counter []uint64 // 计数器数组，每个uint64存放16个计数器
+	lock    sync.RWMutex
+	num     int // 元素个数(Add次数 - Remove次数)
+}
+
+/*
+NewCountingBloom
+param maxN 预计元素数量
+param p 期望误差概率
+*/
+func NewCountingBloom(maxN uint64, p float64) *countingBloom {
+	return NewCountingBloomWithSeed(maxN, p, DefaultSeed)
+}
+
+// NewCountingBloomWithSeed 以指定的哈希种子初始化计数布隆过滤器
+func NewCountingBloomWithSeed(maxN uint64, p float64, seed uint32) *countingBloom {
+	b := NewBloomWithSeed(maxN, p, seed)
+	return &countingBloom{
+		slotNum: b.bitNum,
+		hasher:  b.hasher,
+		counter: make([]uint64, (b.bitNum+counterPer-1)/counterPer),
+	}
+}
+
+// Add 数据插入过滤器 error is always return nil
+func (c *countingBloom) Add(val []byte) error {
+	var buf [16]uint64
+	locations := c.hasher.locations(val, buf[:0])
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, slot := range locations {
+		if v := c.get(slot); v < counterMax {
+			c.set(slot, v+1)
+		}
+	}
+	c.num++
+	return nil
+}
+
+// Remove 从过滤器中删除数据，数据不在过滤器中时返回NotFoundError
+//
+// 只能删除确实加入过的数据，删除误判为存在的数据会导致其他数据被误删
+func (c *countingBloom) Remove(val []byte) error {
+	var buf [16]uint64
+	locations := c.hasher.locations(val, buf[:0])
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, slot := range locations {
+		if c.get(slot) == 0 {
+			return NotFoundError
+		}
+	}
+	for _, slot := range locations {
+		if v := c.get(slot); v > 0 && v < counterMax {
+			c.set(slot, v-1)
+		}
+	}
+	c.num--
+	return nil
+}
+
+// Check 判断数据是否在过滤器中(存在true 不存在false)
+func (c *countingBloom) Check(val []byte) bool {
+	return c.Count(val) > 0
+}
+
+// Count 估算数据被加入的次数(取各槽位计数器的最小值)，只会高估不会低估，最大为15
+func (c *countingBloom) Count(val []byte) int {
+	var buf [16]uint64
+	locations := c.hasher.locations(val, buf[:0])
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	res := uint64(counterMax)
+	for _, slot := range locations {
+		if v := c.get(slot); v < res {
+			res = v
+		}
+	}
+	return int(res)
+}
+
+// Len 元素个数(Add次数 - Remove次数)
+func (c *countingBloom) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.num
+}
+
+func (c *countingBloom) get(slot uint64) uint64 {
+	return c.counter[slot/counterPer] >> (slot % counterPer * counterBits) & counterMask
+}
+
+func (c *countingBloom) set(slot, v uint64) {
+	shift := slot % counterPer * counterBits
+	idx := slot / counterPer
+	c.counter[idx] = c.counter[idx]&^(counterMask<<shift) | v<<shift
+}
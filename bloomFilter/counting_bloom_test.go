@@ -0,0 +1,55 @@
+package bloomFilter
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func Test_CountingBloom(t *testing.T) {
+	c := NewCountingBloom(10000, 0.01)
+	for i := 0; i < 5000; i++ {
+		_ = c.Add([]byte("ticket" + strconv.Itoa(i)))
+	}
+	_ = c.Add([]byte("ticket0"))
+	if n := c.Count([]byte("ticket0")); n < 2 {
+		t.Fatalf("expect count >= 2 got %d", n)
+	}
+	// 删除一半后，剩余数据仍存在，已删除数据大概率不存在
+	for i := 0; i < 2500; i++ {
+		if err := c.Remove([]byte("ticket" + strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 2500; i < 5000; i++ {
+		if !c.Check([]byte("ticket" + strconv.Itoa(i))) {
+			t.Fatalf("ticket%d lost after removing others", i)
+		}
+	}
+	falsePositive := 0
+	for i := 1; i < 2500; i++ {
+		if c.Check([]byte("ticket" + strconv.Itoa(i))) {
+			falsePositive++
+		}
+	}
+	if rate := float64(falsePositive) / 2500; rate > 0.01 {
+		t.Fatalf("false positive rate %f too high after remove", rate)
+	}
+	if !c.Check([]byte("ticket0")) || c.Len() != 2501 {
+		t.Fatalf("expect ticket0 added twice still present, len %d", c.Len())
+	}
+	if err := c.Remove([]byte("never added")); !errors.Is(err, NotFoundError) {
+		t.Fatalf("expect NotFoundError got %v", err)
+	}
+
+	// 计数器饱和后不再减少
+	for i := 0; i < 20; i++ {
+		_ = c.Add([]byte("hot"))
+	}
+	for i := 0; i < 20; i++ {
+		_ = c.Remove([]byte("hot"))
+	}
+	if c.Count([]byte("hot")) != counterMax {
+		t.Fatalf("expect saturated counter got %d", c.Count([]byte("hot")))
+	}
+}
@@ -0,0 +1,42 @@
+package bloomFilter
+
+// hasher 计算数据在位数组中的位置
+type hasher interface {
+	// locations 将数据对应的各个位的下标追加到dst中返回
+	locations(val []byte, dst []uint64) []uint64
+	// count 哈希函数数量
+	count() uint64
+	// equal 两个哈希配置是否相同，相同时同一数据计算出的位置一致
+	equal(o hasher) bool
+}
+
+// doubleHasher Kirsch–Mitzenmacher 双重哈希：一次 murmur3 得到h1、h2，第i个位置为 h1 + i*h2
+//
+// 只需计算一次哈希即可模拟k个独立哈希函数，且误判率与k个独立哈希函数渐近一致
+type doubleHasher struct {
+	hashNum uint64
+	bitNum  uint64
+	seed    uint32
+}
+
+func (h *doubleHasher) locations(val []byte, dst []uint64) []uint64 {
+	h1, h2 := murmur3(val, h.seed)
+	h1 %= h.bitNum
+	if h.bitNum > 1 {
+		// h2取模后为0时k个位置会退化为同一个位，强制其在[1, m)内
+		h2 = h2%(h.bitNum-1) + 1
+	}
+	for i := uint64(0); i < h.hashNum; i++ {
+		dst = append(dst, (h1+i*h2)%h.bitNum)
+	}
+	return dst
+}
+
+func (h *doubleHasher) count() uint64 {
+	return h.hashNum
+}
+
+func (h *doubleHasher) equal(o hasher) bool {
+	d, ok := o.(*doubleHasher)
+	return ok && *h == *d
+}
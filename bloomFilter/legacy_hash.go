@@ -0,0 +1,102 @@
+package bloomFilter
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"hash"
+	"math/rand"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// legacyHasher 旧版哈希：md5/sha1/sha256 三个基础哈希 + 随机盐的sha512
+//
+// 盐由math/rand随机生成，不同进程创建的过滤器互不兼容，仅用于读取旧版(version 1)序列化数据
+type legacyHasher struct {
+	bitNum   uint64
+	salts    [][]byte                  // 加盐哈希函数的盐，序列化时需要保存
+	hashFunc []func(val []byte) uint64 // 哈希函数数组
+}
+
+var hashBaseFunc = []hash.Hash{md5.New(), sha1.New(), sha256.New()}
+var legacyBaseNum = uint64(len(hashBaseFunc))
+
+// newLegacyBloom 以旧版哈希初始化过滤器
+func newLegacyBloom(maxN uint64, p float64) *bloom {
+	var (
+		bitNum  = optimalM(maxN, p)
+		hashNum = optimalK(bitNum, maxN)
+	)
+	if hashNum < legacyBaseNum {
+		hashNum = legacyBaseNum
+	}
+	if hashNum > maxHashNum {
+		hashNum = maxHashNum
+	}
+	return &bloom{
+		bitNum: bitNum,
+		hasher: newLegacyHasher(bitNum, createSalt(hashNum-legacyBaseNum)),
+		bit:    make([]uint64, (bitNum+63)/64),
+		lock:   sync.RWMutex{},
+	}
+}
+
+func (h *legacyHasher) equal(o hasher) bool {
+	l, ok := o.(*legacyHasher)
+	return ok && h.bitNum == l.bitNum && slices.EqualFunc(h.salts, l.salts, bytes.Equal)
+}
+
+// newLegacyHasher 以基础哈希函数 + 指定的盐初始化哈希函数数组
+func newLegacyHasher(bitNum uint64, salts [][]byte) *legacyHasher {
+	h := &legacyHasher{bitNum: bitNum, salts: salts}
+	h.baseHash()
+	for _, salt := range salts {
+		h.hashFunc = append(h.hashFunc, h.createHash(salt))
+	}
+	return h
+}
+
+func (h *legacyHasher) locations(val []byte, dst []uint64) []uint64 {
+	for _, hs := range h.hashFunc {
+		dst = append(dst, hs(val))
+	}
+	return dst
+}
+
+func (h *legacyHasher) count() uint64 {
+	return uint64(len(h.hashFunc))
+}
+
+// 生成hash函数createHash
+func (h *legacyHasher) createHash(salt []byte) func(val []byte) uint64 {
+	return func(val []byte) uint64 {
+		hs := sha512.New()
+		res := hs.Sum(append(val, salt...))
+		return binary.BigEndian.Uint64(res[:8]) % h.bitNum
+	}
+}
+
+// 基础的hash函数(不加盐)
+func (h *legacyHasher) baseHash() {
+	for _, hs := range hashBaseFunc {
+		var f = func(val []byte) uint64 {
+			res := hs.Sum(val)
+			return binary.BigEndian.Uint64(res[:8]) % h.bitNum
+		}
+		h.hashFunc = append(h.hashFunc, f)
+	}
+}
+
+// 生成指定位数的salt
+func createSalt(num uint64) [][]byte {
+	var res = make([][]byte, 0)
+	for i := uint64(0); i < num; i++ {
+		res = append(res, []byte(string(rand.Int31())+strconv.FormatUint(i, 10)))
+	}
+	return res
+}
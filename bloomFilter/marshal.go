@@ -0,0 +1,251 @@
+package bloomFilter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+)
+
+// 序列化格式(小端序)：
+//
+//	magic(4) | version(1) | bitNum(8) | num(8) | 哈希配置 | bit([]uint64) | crc32(4)
+//
+// 哈希配置 version 1: saltNum(4) | [saltLen(4) | salt]...
+// 哈希配置 version 2: hashNum(4) | seed(4)
+//
+// crc32校验覆盖crc之前的全部字节
+var bloomMagic = [4]byte{'B', 'L', 'O', 'M'}
+
+const (
+	bloomVersion1  = 1           // 旧版哈希(基础哈希 + 随机盐)，仅支持读取
+	bloomVersion2  = 2           // murmur3 双重哈希
+	maxSaltLen     = 1 << 16     // 单个盐的最大长度，防止读取异常数据时分配过大的内存
+	maxBitNum      = 1 << 40     // 最大位数量(128G)，同上
+	headerLen      = 4 + 1 + 8*2 // magic + version + bitNum + num
+	readChunkWords = 1 << 13     // 读取位数组时每次读取的uint64数量(64K)
+)
+
+var FormatError = errors.New("bloom: invalid data format")  // 数据格式错误
+var VersionError = errors.New("bloom: unsupported version") // 不支持的版本
+var ChecksumError = errors.New("bloom: checksum mismatch")  // 校验和不一致，数据已损坏
+
+var _ interface {
+	io.WriterTo
+	io.ReaderFrom
+} = (*bloom)(nil)
+
+// MarshalBinary 序列化过滤器，包含位数组、元素数量及哈希配置
+func (b *bloom) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := b.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary 从 MarshalBinary 的结果中恢复过滤器，覆盖当前的全部数据
+func (b *bloom) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	if _, err := b.ReadFrom(r); err != nil {
+		return err
+	}
+	if r.Len() > 0 {
+		return FormatError
+	}
+	return nil
+}
+
+// WriteTo 将过滤器序列化写入w
+func (b *bloom) WriteTo(w io.Writer) (int64, error) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	var (
+		cw  = &countWriter{w: w}
+		crc = crc32.NewIEEE()
+		out = io.MultiWriter(cw, crc)
+	)
+	header := make([]byte, 0, headerLen)
+	header = append(header, bloomMagic[:]...)
+	switch h := b.hasher.(type) {
+	case *legacyHasher:
+		header = append(header, bloomVersion1)
+		header = binary.LittleEndian.AppendUint64(header, b.bitNum)
+		header = binary.LittleEndian.AppendUint64(header, uint64(b.num))
+		header = binary.LittleEndian.AppendUint32(header, uint32(len(h.salts)))
+		for _, salt := range h.salts {
+			header = binary.LittleEndian.AppendUint32(header, uint32(len(salt)))
+			header = append(header, salt...)
+		}
+	case *doubleHasher:
+		header = append(header, bloomVersion2)
+		header = binary.LittleEndian.AppendUint64(header, b.bitNum)
+		header = binary.LittleEndian.AppendUint64(header, uint64(b.num))
+		header = binary.LittleEndian.AppendUint32(header, uint32(h.hashNum))
+		header = binary.LittleEndian.AppendUint32(header, h.seed)
+	default:
+		return 0, VersionError
+	}
+	if _, err := out.Write(header); err != nil {
+		return cw.n, err
+	}
+	if err := binary.Write(out, binary.LittleEndian, b.bit); err != nil {
+		return cw.n, err
+	}
+	_, err := cw.Write(binary.LittleEndian.AppendUint32(nil, crc.Sum32()))
+	return cw.n, err
+}
+
+// ReadFrom 从r读取 WriteTo 写入的数据并恢复过滤器，覆盖当前的全部数据。数据损坏时返回ChecksumError
+func (b *bloom) ReadFrom(r io.Reader) (int64, error) {
+	var (
+		cr  = &countReader{r: r}
+		crc = crc32.NewIEEE()
+		in  = io.TeeReader(cr, crc)
+	)
+	header := make([]byte, headerLen)
+	if _, err := io.ReadFull(in, header); err != nil {
+		return cr.n, unexpectedEOF(err)
+	}
+	if !bytes.Equal(header[:4], bloomMagic[:]) {
+		return cr.n, FormatError
+	}
+	bitNum := binary.LittleEndian.Uint64(header[5:])
+	num := binary.LittleEndian.Uint64(header[13:])
+	if bitNum == 0 || bitNum > maxBitNum {
+		return cr.n, FormatError
+	}
+	var (
+		h   hasher
+		err error
+	)
+	switch header[4] {
+	case bloomVersion1:
+		h, err = readLegacyHasher(in, bitNum)
+	case bloomVersion2:
+		h, err = readDoubleHasher(in, bitNum)
+	default:
+		err = VersionError
+	}
+	if err != nil {
+		return cr.n, err
+	}
+	bit, err := readBits(in, (bitNum+63)/64)
+	if err != nil {
+		return cr.n, err
+	}
+	sum := crc.Sum32()
+	var checksum [4]byte
+	if _, err = io.ReadFull(cr, checksum[:]); err != nil {
+		return cr.n, unexpectedEOF(err)
+	}
+	if binary.LittleEndian.Uint32(checksum[:]) != sum {
+		return cr.n, ChecksumError
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.bitNum = bitNum
+	b.num = int(num)
+	b.bit = bit
+	b.ones = popCount(bit)
+	b.hasher = h
+	return cr.n, nil
+}
+
+// readBits 分块读取位数组，随数据到达逐步扩容，头部的bitNum被篡改或数据被截断时不会预先分配巨大的内存
+func readBits(r io.Reader, words uint64) ([]uint64, error) {
+	bit := make([]uint64, 0, min(words, readChunkWords))
+	buf := make([]byte, 8*min(words, readChunkWords))
+	for remain := words; remain > 0; {
+		chunk := buf[:8*min(remain, readChunkWords)]
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		for i := 0; i < len(chunk); i += 8 {
+			bit = append(bit, binary.LittleEndian.Uint64(chunk[i:]))
+		}
+		remain -= uint64(len(chunk) / 8)
+	}
+	return bit, nil
+}
+
+// readLegacyHasher 读取 version 1 的哈希配置
+func readLegacyHasher(r io.Reader, bitNum uint64) (hasher, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	saltNum := binary.LittleEndian.Uint32(size[:])
+	if uint64(saltNum)+legacyBaseNum > maxHashNum {
+		return nil, FormatError
+	}
+	salts := make([][]byte, saltNum)
+	for i := range salts {
+		if _, err := io.ReadFull(r, size[:]); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		n := binary.LittleEndian.Uint32(size[:])
+		if n > maxSaltLen {
+			return nil, FormatError
+		}
+		salts[i] = make([]byte, n)
+		if _, err := io.ReadFull(r, salts[i]); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+	}
+	return newLegacyHasher(bitNum, salts), nil
+}
+
+// readDoubleHasher 读取 version 2 的哈希配置
+func readDoubleHasher(r io.Reader, bitNum uint64) (hasher, error) {
+	var buf [8]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	hashNum := uint64(binary.LittleEndian.Uint32(buf[:]))
+	if hashNum < minHashNum || hashNum > maxHashNum {
+		return nil, FormatError
+	}
+	return &doubleHasher{hashNum: hashNum, bitNum: bitNum, seed: binary.LittleEndian.Uint32(buf[4:])}, nil
+}
+
+// LoadBloom 从r中读取并恢复过滤器
+func LoadBloom(r io.Reader) (*bloom, error) {
+	b := &bloom{}
+	if _, err := b.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// unexpectedEOF 数据提前结束时统一返回FormatError
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return FormatError
+	}
+	return err
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type countReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
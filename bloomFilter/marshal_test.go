@@ -0,0 +1,93 @@
+package bloomFilter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func Test_BloomMarshal(t *testing.T) {
+	t.Run("v2", func(t *testing.T) { testBloomMarshal(t, NewBloom(10000, 0.01)) })
+	t.Run("v1", func(t *testing.T) { testBloomMarshal(t, newLegacyBloom(10000, 0.01)) })
+}
+
+func testBloomMarshal(t *testing.T, b *bloom) {
+	for i := 0; i < 1000; i++ {
+		_ = b.Add([]byte("key" + strconv.Itoa(i)))
+	}
+	data, err := b.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if n, err := b.WriteTo(&buf); err != nil || n != int64(len(data)) || !bytes.Equal(buf.Bytes(), data) {
+		t.Fatalf("WriteTo mismatch MarshalBinary: %d %v", n, err)
+	}
+	loaded, err := LoadBloom(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := NewBloom(1, 0.5)
+	if err = restored.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []*bloom{loaded, restored} {
+		if f.bitNum != b.bitNum || f.hasher.count() != b.hasher.count() {
+			t.Fatalf("unexpected config %d %d", f.bitNum, f.hasher.count())
+		}
+		for i := 0; i < 1000; i++ {
+			if !f.Check([]byte("key" + strconv.Itoa(i))) {
+				t.Fatalf("key%d lost after unmarshal", i)
+			}
+		}
+		// 哈希配置一致，未加入的数据判定结果也应一致
+		for i := 1000; i < 2000; i++ {
+			key := []byte("key" + strconv.Itoa(i))
+			if f.Check(key) != b.Check(key) {
+				t.Fatalf("check %s differs after unmarshal", key)
+			}
+		}
+	}
+
+	corrupted := append([]byte(nil), data...)
+	corrupted[len(corrupted)/2] ^= 0xff
+	if err = restored.UnmarshalBinary(corrupted); !errors.Is(err, ChecksumError) {
+		t.Fatalf("expect ChecksumError got %v", err)
+	}
+	if err = restored.UnmarshalBinary(data[:len(data)-1]); !errors.Is(err, FormatError) {
+		t.Fatalf("expect FormatError got %v", err)
+	}
+	if err = restored.UnmarshalBinary(append(data, 0)); !errors.Is(err, FormatError) {
+		t.Fatalf("expect FormatError for trailing data got %v", err)
+	}
+	badVersion := append([]byte(nil), data...)
+	badVersion[4] = 99
+	if err = restored.UnmarshalBinary(badVersion); !errors.Is(err, VersionError) {
+		t.Fatalf("expect VersionError got %v", err)
+	}
+}
+
+func Test_BloomUnmarshalHugeHeader(t *testing.T) {
+	// 头部声明最大的位数量，但数据在位数组中途截断
+	data := append([]byte(nil), bloomMagic[:]...)
+	data = append(data, bloomVersion2)
+	data = binary.LittleEndian.AppendUint64(data, maxBitNum)
+	data = binary.LittleEndian.AppendUint64(data, 0)
+	data = binary.LittleEndian.AppendUint32(data, 7)
+	data = binary.LittleEndian.AppendUint32(data, DefaultSeed)
+	data = append(data, make([]byte, 1024)...)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if _, err := LoadBloom(bytes.NewReader(data)); !errors.Is(err, FormatError) {
+		t.Fatalf("expect FormatError got %v", err)
+	}
+	runtime.ReadMemStats(&after)
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > 4<<20 {
+		t.Fatalf("truncated data allocated %d bytes", alloc)
+	}
+}
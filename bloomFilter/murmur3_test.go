@@ -0,0 +1,21 @@
+package bloomFilter
+
+import "testing"
+
+func Test_Murmur3(t *testing.T) {
+	for _, c := range []struct {
+		data   string
+		seed   uint32
+		h1, h2 uint64
+	}{
+		{"", 0, 0, 0},
+		{"hello", 0, 0xcbd8a7b341bd9b02, 0x5b1e906a48ae1d19},
+		{"hello, world", 0, 0x342fac623a5ebc8e, 0x4cdcbc079642414d},
+		{"19 Jan 2038 at 3:14:07 AM", 0, 0xb89e5988b737affc, 0x664fc2950231b2cb},
+		{"The quick brown fox jumps over the lazy dog.", 0, 0xcd99481f9ee902c9, 0x695da1a38987b6e7},
+	} {
+		if h1, h2 := murmur3([]byte(c.data), c.seed); h1 != c.h1 || h2 != c.h2 {
+			t.Fatalf("murmur3(%q, %d) = %x %x, expect %x %x", c.data, c.seed, h1, h2, c.h1, c.h2)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package bloomFilter
+
+import (
+	"math"
+	"sync"
+)
+
+const (
+	defaultGrowth     = 2   // 默认容量增长倍数
+	defaultTightening = 0.8 // 默认误差收紧比例
+	defaultFillRatio  = 0.5 // 默认扩容阈值，按最优参数设计的过滤器装满时约一半的位为1
+)
+
+// ScalableOption 可扩容布隆过滤器的配置项
+type ScalableOption func(*scalableBloom)
+
+// WithGrowth 每个新的子过滤器容量是上一个的s倍，s至少为1
+func WithGrowth(s uint64) ScalableOption {
+	return func(sb *scalableBloom) {
+		if s >= 1 {
+			sb.growth = s
+		}
+	}
+}
+
+// WithTightening 每个新的子过滤器的误差概率是上一个的r倍，r取值(0, 1)，越小误差越低、占用内存越多
+func WithTightening(r float64) ScalableOption {
+	return func(sb *scalableBloom) {
+		if r > 0 && r < 1 {
+			sb.tightening = r
+		}
+	}
+}
+
+// WithFillRatio 当前子过滤器中置1的位比例达到ratio时扩容，ratio取值(0, 1)
+func WithFillRatio(ratio float64) ScalableOption {
+	return func(sb *scalableBloom) {
+		if ratio > 0 && ratio < 1 {
+			sb.fillRatio = ratio
+		}
+	}
+}
+
+// scalableBloom 可扩容布隆过滤器，元素数量超过预期时不会导致误差概率失控
+//
+// 数据只写入最新的子过滤器，当其填充率达到阈值时新增一个容量更大、误差更低的子过滤器。
+// 第i个子过滤器的误差概率为 p*(1-r)*r^i，各子过滤器误差之和不超过p
+type scalableBloom struct {
+	filters    []*bloom
+	initN      uint64  // 第一个子过滤器的预计元素数量
+	p          float64 // 整体期望误差概率
+	growth     uint64
+	tightening float64
+	fillRatio  float64
+	lock       sync.RWMutex
+	num        int // 元素个数(重复数据不计数)
+}
+
+/*
+NewScalableBloom
+param initN 第一个子过滤器的预计元素数量
+param p 整体期望误差概率
+*/
+func NewScalableBloom(initN uint64, p float64, opts ...ScalableOption) *scalableBloom {
+	sb := &scalableBloom{
+		initN:      max(initN, 1),
+		p:          p,
+		growth:     defaultGrowth,
+		tightening: defaultTightening,
+		fillRatio:  defaultFillRatio,
+	}
+	for _, opt := range opts {
+		opt(sb)
+	}
+	sb.grow()
+	return sb
+}
+
+// Check 判断数据是否在过滤器中(存在true 不存在false)
+func (sb *scalableBloom) Check(val []byte) bool {
+	sb.lock.RLock()
+	defer sb.lock.RUnlock()
+	return sb.check(val)
+}
+
+// Add 数据插入过滤器(不可删除)，已存在的数据不重复写入 error is always return nil
+func (sb *scalableBloom) Add(val []byte) error {
+	sb.lock.Lock()
+	defer sb.lock.Unlock()
+	if sb.check(val) {
+		return nil
+	}
+	var buf [16]uint64
+	last := sb.filters[len(sb.filters)-1]
+	if last.add(last.hasher.locations(val, buf[:0])) {
+		sb.num++
+	}
+	if last.fillRatio() >= sb.fillRatio {
+		sb.grow()
+	}
+	return nil
+}
+
+// Len 元素个数
+func (sb *scalableBloom) Len() int {
+	sb.lock.RLock()
+	defer sb.lock.RUnlock()
+	return sb.num
+}
+
+// FilterNum 子过滤器数量
+func (sb *scalableBloom) FilterNum() int {
+	sb.lock.RLock()
+	defer sb.lock.RUnlock()
+	return len(sb.filters)
+}
+
+// check 依次检查各个子过滤器，调用方需持有锁。子过滤器由sb的锁保护，不再单独加锁
+func (sb *scalableBloom) check(val []byte) bool {
+	var buf [16]uint64
+	for _, f := range sb.filters {
+		if checkBits(f.bit, f.hasher.locations(val, buf[:0])) {
+			return true
+		}
+	}
+	return false
+}
+
+// grow 新增一个子过滤器，容量按growth倍增长，误差按tightening倍收紧
+func (sb *scalableBloom) grow() {
+	i := len(sb.filters)
+	n := sb.initN * uint64(math.Pow(float64(sb.growth), float64(i)))
+	p := sb.p * (1 - sb.tightening) * math.Pow(sb.tightening, float64(i))
+	// 不同子过滤器使用不同的种子，避免同一数据在各子过滤器中的位置相关
+	sb.filters = append(sb.filters, NewBloomWithSeed(n, p, DefaultSeed+uint32(i)))
+}
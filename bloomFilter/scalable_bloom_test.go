@@ -0,0 +1,69 @@
+package bloomFilter
+
+import (
+	"strconv"
+	"testing"
+)
+
+func Test_ScalableBloom(t *testing.T) {
+	const n = 100000
+	sb := NewScalableBloom(1000, 0.01)
+	for i := 0; i < n; i++ {
+		_ = sb.Add([]byte("ticket" + strconv.Itoa(i)))
+	}
+	for i := 0; i < n; i++ {
+		if !sb.Check([]byte("ticket" + strconv.Itoa(i))) {
+			t.Fatalf("ticket%d lost", i)
+		}
+	}
+	if sb.FilterNum() < 2 {
+		t.Fatalf("expect filter grown got %d", sb.FilterNum())
+	}
+	// 重复数据不计数，误判为已存在的数据也不计数，因此Len略少于n
+	if l := sb.Len(); l > n || l < n*99/100 {
+		t.Fatalf("unexpected len %d", l)
+	}
+	_ = sb.Add([]byte("ticket0"))
+	if sb.Len() > n {
+		t.Fatalf("duplicate counted, len %d", sb.Len())
+	}
+
+	// 元素数量远超初始容量后误差仍不超过p
+	falsePositive := 0
+	for i := 0; i < n; i++ {
+		if sb.Check([]byte("other" + strconv.Itoa(i))) {
+			falsePositive++
+		}
+	}
+	if rate := float64(falsePositive) / n; rate > 0.01 {
+		t.Fatalf("false positive rate %f exceeds 0.01", rate)
+	}
+
+	// 对比固定容量过滤器，超出容量后误差失控
+	b := NewBloom(1000, 0.01)
+	for i := 0; i < n; i++ {
+		_ = b.Add([]byte("ticket" + strconv.Itoa(i)))
+	}
+	if b.fillRatio() < 0.99 {
+		t.Fatalf("expect fixed size bloom saturated, fill ratio %f", b.fillRatio())
+	}
+}
+
+func Test_BloomLen(t *testing.T) {
+	b := NewBloom(1000, 0.01)
+	for i := 0; i < 100; i++ {
+		_ = b.Add([]byte(strconv.Itoa(i)))
+		_ = b.Add([]byte(strconv.Itoa(i)))
+	}
+	if b.Len() != 100 {
+		t.Fatalf("expect len 100 got %d", b.Len())
+	}
+	data, _ := b.MarshalBinary()
+	f := &bloom{}
+	if err := f.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if f.Len() != 100 || f.ones != b.ones {
+		t.Fatalf("unexpected len %d ones %d", f.Len(), f.ones)
+	}
+}
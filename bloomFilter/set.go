@@ -0,0 +1,87 @@
+package bloomFilter
+
+import (
+	"errors"
+	"math"
+)
+
+var IncompatibleError = errors.New("bloom: incompatible filters") // 位数量或哈希配置不同的过滤器无法合并
+
+// Clone 深拷贝过滤器
+func (b *bloom) Clone() *bloom {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return &bloom{
+		bitNum: b.bitNum,
+		hasher: b.hasher, // 哈希配置创建后不再修改，可以共享
+		bit:    append([]uint64(nil), b.bit...),
+		num:    b.num,
+		ones:   b.ones,
+	}
+}
+
+// Union 将other合并到当前过滤器(按位或)，合并后包含两者的全部数据。
+// 两者位数量与哈希配置(种子)必须相同，否则返回IncompatibleError
+//
+// 合并后的元素个数无法精确得知，取 EstimatedCount 的估算值
+func (b *bloom) Union(other *bloom) error {
+	return b.merge(other, func(dst, src uint64) uint64 { return dst | src })
+}
+
+// Intersect 与other求交集(按位与)，结果包含两者共同的数据，但误差概率高于直接用共同数据构建的过滤器。
+// 两者位数量与哈希配置(种子)必须相同，否则返回IncompatibleError
+func (b *bloom) Intersect(other *bloom) error {
+	return b.merge(other, func(dst, src uint64) uint64 { return dst & src })
+}
+
+// merge 先在other的读锁下拷贝其位数组，再在b的写锁下合并，避免同时持有两把锁导致死锁
+func (b *bloom) merge(other *bloom, op func(dst, src uint64) uint64) error {
+	if b == other {
+		return nil
+	}
+	other.lock.RLock()
+	bitNum, h, bit := other.bitNum, other.hasher, append([]uint64(nil), other.bit...)
+	other.lock.RUnlock()
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.bitNum != bitNum || !b.hasher.equal(h) {
+		return IncompatibleError
+	}
+	for i := range b.bit {
+		b.bit[i] = op(b.bit[i], bit[i])
+	}
+	b.ones = popCount(b.bit)
+	b.num = int(min(b.estimatedCount(), math.MaxInt))
+	return nil
+}
+
+// EstimatedCount 根据置1的位数量估算元素个数：n ≈ -(m/k)·ln(1 - X/m)。
+// 位数组全部为1(已饱和)时无法估算，返回math.MaxUint64
+func (b *bloom) EstimatedCount() uint64 {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return b.estimatedCount()
+}
+
+// FillRatio 位数组中置1的位所占比例，超过0.5说明元素数量已超出预期，误差概率开始快速升高
+func (b *bloom) FillRatio() float64 {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return b.fillRatio()
+}
+
+// CurrentFalsePositiveRate 按当前填充率计算的误差概率：(X/m)^k
+func (b *bloom) CurrentFalsePositiveRate() float64 {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return math.Pow(b.fillRatio(), float64(b.hasher.count()))
+}
+
+func (b *bloom) estimatedCount() uint64 {
+	if b.ones >= b.bitNum {
+		return math.MaxUint64
+	}
+	m, k := float64(b.bitNum), float64(b.hasher.count())
+	return uint64(math.Round(-m / k * math.Log1p(-float64(b.ones)/m)))
+}
@@ -0,0 +1,91 @@
+package bloomFilter
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func Test_BloomSet(t *testing.T) {
+	a, b := NewBloom(10000, 0.01), NewBloom(10000, 0.01)
+	for i := 0; i < 3000; i++ {
+		_ = a.Add([]byte("shard" + strconv.Itoa(i)))
+	}
+	for i := 2000; i < 5000; i++ {
+		_ = b.Add([]byte("shard" + strconv.Itoa(i)))
+	}
+
+	union := a.Clone()
+	if err := union.Union(b); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5000; i++ {
+		if !union.Check([]byte("shard" + strconv.Itoa(i))) {
+			t.Fatalf("shard%d lost after union", i)
+		}
+	}
+	if n := union.Len(); math.Abs(float64(n)-5000) > 100 {
+		t.Fatalf("unexpected union len %d", n)
+	}
+	// Clone后修改不影响原过滤器
+	if a.Check([]byte("shard4999")) {
+		t.Fatal("clone shares bits with origin")
+	}
+
+	inter := a.Clone()
+	if err := inter.Intersect(b); err != nil {
+		t.Fatal(err)
+	}
+	for i := 2000; i < 3000; i++ {
+		if !inter.Check([]byte("shard" + strconv.Itoa(i))) {
+			t.Fatalf("shard%d lost after intersect", i)
+		}
+	}
+	falsePositive := 0
+	for i := 0; i < 2000; i++ {
+		if inter.Check([]byte("shard" + strconv.Itoa(i))) {
+			falsePositive++
+		}
+	}
+	if falsePositive > 100 {
+		t.Fatalf("too many false positive after intersect: %d", falsePositive)
+	}
+
+	if err := a.Union(NewBloom(20000, 0.01)); !errors.Is(err, IncompatibleError) {
+		t.Fatalf("expect IncompatibleError got %v", err)
+	}
+	if err := a.Intersect(NewBloomWithSeed(10000, 0.01, 1)); !errors.Is(err, IncompatibleError) {
+		t.Fatalf("expect IncompatibleError got %v", err)
+	}
+	if err := a.Union(a); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_BloomEstimate(t *testing.T) {
+	b := NewBloom(10000, 0.01)
+	if b.EstimatedCount() != 0 || b.FillRatio() != 0 || b.CurrentFalsePositiveRate() != 0 {
+		t.Fatal("expect empty filter")
+	}
+	for i := 0; i < 10000; i++ {
+		_ = b.Add([]byte("ticket" + strconv.Itoa(i)))
+	}
+	if n := b.EstimatedCount(); math.Abs(float64(n)-10000) > 200 {
+		t.Fatalf("unexpected estimated count %d", n)
+	}
+	// 按最优参数装满时约一半的位为1，误差接近设计值
+	if r := b.FillRatio(); math.Abs(r-0.5) > 0.02 {
+		t.Fatalf("unexpected fill ratio %f", r)
+	}
+	if p := b.CurrentFalsePositiveRate(); p < 0.005 || p > 0.015 {
+		t.Fatalf("unexpected false positive rate %f", p)
+	}
+
+	for i := 10000; i < 100000; i++ {
+		_ = b.Add([]byte("ticket" + strconv.Itoa(i)))
+	}
+	if p := b.CurrentFalsePositiveRate(); p < 0.5 {
+		t.Fatalf("expect saturated filter, false positive rate %f", p)
+	}
+}
@@ -0,0 +1,162 @@
+package delayQueue
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// AckDelayQueue 至少一次投递的延迟队列(确认模式)
+//
+// Watch 返回的是消息租约而非直接出队：消息在投递的同时以"当前时间+可见性超时"重新入队，
+// 消费者在超时前调用 Ack 才会真正移除，调用 Nack 则按退避时间重新入队；
+// 未确认的消息会在可见性超时后自动重投，投递次数记录在 MessageItem.Attempts 中。
+// 周期消息触发后底层队列会以同一个key加入下一次触发，与重投冲突，因此确认模式不支持周期消息，Add时返回ScheduleError。
+type AckDelayQueue[T messageTyps] struct {
+	q          DelayQueue[T]                    // 底层延迟队列
+	visibility time.Duration                    // 可见性超时
+	backoff    func(attempts int) time.Duration // Nack后的重投延迟
+	inflight   map[string]int                   // 投递中的消息key -> 当前有效租约的投递次数
+	clock      Clock                            // 时钟
+	lock       sync.Mutex
+}
+
+// Lease 消息租约，租约有效期内需调用 Ack 或 Nack
+type Lease[T messageTyps] struct {
+	aq       *AckDelayQueue[T]
+	item     *MessageItem[T]
+	deadline time.Time
+}
+
+// NewAckDelayQe 在已有延迟队列之上开启确认模式
+//
+// @param q 底层延迟队列，确认模式接管后不应再直接调用其Watch
+func NewAckDelayQe[T messageTyps](q DelayQueue[T], opts ...Option) *AckDelayQueue[T] {
+	o := newOptions(opts)
+	return &AckDelayQueue[T]{
+		q:          q,
+		visibility: o.visibility,
+		backoff:    o.nackBackoff,
+		inflight:   make(map[string]int),
+		clock:      o.clock,
+	}
+}
+
+// Add 添加任务，周期消息返回ScheduleError
+func (aq *AckDelayQueue[T]) Add(msg *MessageItem[T]) error {
+	if msg.schedule != nil {
+		return ScheduleError
+	}
+	aq.lock.Lock()
+	defer aq.lock.Unlock()
+	return aq.q.Add(msg)
+}
+
+// Delete 移除任务，投递中的消息其租约同时失效
+func (aq *AckDelayQueue[T]) Delete(key string) error {
+	aq.lock.Lock()
+	defer aq.lock.Unlock()
+	delete(aq.inflight, key)
+	return aq.q.Delete(key)
+}
+
+// Search 查询任务(投递中的消息同样可以查到)
+func (aq *AckDelayQueue[T]) Search(key string) (*MessageItem[T], error) {
+	return aq.q.Search(key)
+}
+
+// Len 未确认的任务数量(含投递中的消息)
+func (aq *AckDelayQueue[T]) Len() int {
+	return aq.q.Len()
+}
+
+// Close 关闭底层队列
+func (aq *AckDelayQueue[T]) Close() {
+	aq.q.Close()
+}
+
+// Watch 阻塞直到有消息到期，返回该消息的租约
+//
+// 消息无法重新入队(如出队后该key已被重新添加、队列已满)时返回对应错误且不返回租约，避免投递无法重投的消息
+func (aq *AckDelayQueue[T]) Watch(ctx context.Context) (*Lease[T], error) {
+	msg, err := aq.q.Watch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	msg.attempts++
+	lease := &Lease[T]{aq: aq, item: msg, deadline: aq.clock.Now().Add(aq.visibility)}
+	redeliver := msg.clone()
+	redeliver.sec = lease.deadline
+	// 在锁外重新入队，底层队列按BlockWhenFull阻塞时不影响其他租约的Ack/Nack
+	if err = aq.q.Add(redeliver); err != nil {
+		return nil, err
+	}
+	aq.lock.Lock()
+	defer aq.lock.Unlock()
+	// 重新入队后、加锁前该key已被Delete时，本次投递作废
+	if cur, _ := aq.q.Search(msg.id()); cur != redeliver {
+		return nil, LeaseError
+	}
+	aq.inflight[msg.id()] = msg.attempts
+	return lease, nil
+}
+
+// Item 租约对应的消息
+func (l *Lease[T]) Item() *MessageItem[T] {
+	return l.item
+}
+
+// Deadline 租约到期时间，到期未确认的消息会被重投
+func (l *Lease[T]) Deadline() time.Time {
+	return l.deadline
+}
+
+// Ack 确认消费成功，移除消息。租约已失效时返回LeaseError
+func (l *Lease[T]) Ack() error {
+	aq := l.aq
+	aq.lock.Lock()
+	defer aq.lock.Unlock()
+	if err := aq.release(l); err != nil {
+		return err
+	}
+	return aq.q.Delete(l.item.id())
+}
+
+// Nack 消费失败，按退避时间重新入队。租约已失效时返回LeaseError
+func (l *Lease[T]) Nack() error {
+	aq := l.aq
+	aq.lock.Lock()
+	defer aq.lock.Unlock()
+	if err := aq.release(l); err != nil {
+		return err
+	}
+	if err := aq.q.Delete(l.item.id()); err != nil {
+		return err
+	}
+	retry := l.item.clone()
+	retry.sec = aq.clock.Now().Add(aq.backoff(l.item.attempts))
+	return aq.q.Add(retry)
+}
+
+// release 校验租约是否仍然有效，有效则释放
+func (aq *AckDelayQueue[T]) release(l *Lease[T]) error {
+	if attempts, ok := aq.inflight[l.item.id()]; !ok || attempts != l.item.attempts {
+		return LeaseError
+	}
+	delete(aq.inflight, l.item.id())
+	return nil
+}
+
+// ExponentialBackoff 指数退避：base * 2^(attempts-1)，不超过max
+func ExponentialBackoff(base, max time.Duration) func(attempts int) time.Duration {
+	return func(attempts int) time.Duration {
+		d := base
+		for i := 1; i < attempts && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		return d
+	}
+}
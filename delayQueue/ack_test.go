@@ -0,0 +1,118 @@
+package delayQueue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_AckRedeliver(t *testing.T) {
+	aq := NewAckDelayQe[string](NewDelayQe[string](), WithVisibilityTimeout(200*time.Millisecond))
+	defer aq.Close()
+	_ = aq.Add(NewMessageItem("k1", "v1", time.Now()))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	first, err := aq.Watch(ctx)
+	if err != nil || first.Item().Attempts() != 1 {
+		t.Fatalf("expect first delivery got %v %v", first, err)
+	}
+	if aq.Len() != 1 {
+		t.Fatalf("expect invisible item still queued, len %d", aq.Len())
+	}
+	// 不确认，等待可见性超时后重投
+	second, err := aq.Watch(ctx)
+	if err != nil || second.Item().Attempts() != 2 {
+		t.Fatalf("expect redelivery got %v %v", second, err)
+	}
+	if time.Since(first.Deadline()) < 0 {
+		t.Fatal("redelivered before visibility timeout")
+	}
+	if err = first.Ack(); !errors.Is(err, LeaseError) {
+		t.Fatalf("expect LeaseError got %v", err)
+	}
+	if err = second.Ack(); err != nil {
+		t.Fatal(err)
+	}
+	if aq.Len() != 0 {
+		t.Fatalf("expect empty queue after ack, len %d", aq.Len())
+	}
+}
+
+func Test_AckNack(t *testing.T) {
+	aq := NewAckDelayQe[string](New[string](), WithVisibilityTimeout(time.Hour), WithNackBackoff(ExponentialBackoff(100*time.Millisecond, time.Second)))
+	defer aq.Close()
+	_ = aq.Add(NewMessageItem("k1", "v1", time.Now()))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	for attempts := 1; attempts <= 3; attempts++ {
+		start := time.Now()
+		lease, err := aq.Watch(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lease.Item().Attempts() != attempts {
+			t.Fatalf("expect attempts %d got %d", attempts, lease.Item().Attempts())
+		}
+		if attempts > 1 && time.Since(start) < 50*time.Millisecond {
+			t.Fatal("nack redelivered without backoff")
+		}
+		if err = lease.Nack(); err != nil {
+			t.Fatal(err)
+		}
+		if err = lease.Nack(); !errors.Is(err, LeaseError) {
+			t.Fatalf("expect LeaseError got %v", err)
+		}
+	}
+	if err := aq.Delete("k1"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_ExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(time.Second, 5*time.Second)
+	for attempts, expect := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		if got := backoff(attempts); got != expect {
+			t.Fatalf("attempts %d expect %v got %v", attempts, expect, got)
+		}
+	}
+}
+
+func Test_AckRejectSchedule(t *testing.T) {
+	aq := NewAckDelayQe[string](NewDelayQe[string]())
+	defer aq.Close()
+	if err := aq.Add(NewMessageItem("job", "job", time.Now(), WithSchedule(Every(time.Second)))); !errors.Is(err, ScheduleError) {
+		t.Fatalf("expect ScheduleError got %v", err)
+	}
+	if aq.Len() != 0 {
+		t.Fatalf("expect empty queue, len %d", aq.Len())
+	}
+}
+
+// failingAddQueue 首次之后的Add均失败，模拟重投时无法重新入队
+type failingAddQueue[T messageTyps] struct {
+	*HeapDelayQueue[T]
+	added bool
+}
+
+func (q *failingAddQueue[T]) Add(msg *MessageItem[T]) error {
+	if q.added {
+		return FullError
+	}
+	q.added = true
+	return q.HeapDelayQueue.Add(msg)
+}
+
+func Test_AckRedeliverAddFailed(t *testing.T) {
+	aq := NewAckDelayQe[string](&failingAddQueue[string]{HeapDelayQueue: NewDelayQe[string]()})
+	defer aq.Close()
+	_ = aq.Add(NewMessageItem("k1", "v1", time.Now()))
+	lease, err := aq.Watch(context.Background())
+	if !errors.Is(err, FullError) || lease != nil {
+		t.Fatalf("expect FullError without lease got %v %v", lease, err)
+	}
+	if len(aq.inflight) != 0 || aq.Len() != 0 {
+		t.Fatalf("expect no inflight message, len %d", aq.Len())
+	}
+}
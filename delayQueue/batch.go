@@ -0,0 +1,104 @@
+package delayQueue
+
+import "math/bits"
+
+// AddBatch 批量添加任务，只加锁一次、重置一次定时器，适用于启动时大量加载
+//
+// 新增数量较多时整体重新建堆(O(n))，否则逐个入堆(O(klog2n))。达到容量上限时不会阻塞，BlockWhenFull按RejectWhenFull处理。
+// key已存在或批内重复的消息不会加入，返回的 *BatchError 中记录每个失败key的错误，全部成功时返回nil
+func (hd *HeapDelayQueue[T]) AddBatch(msgs ...*MessageItem[T]) error {
+	hd.lock.Lock()
+	defer hd.lock.Unlock()
+	var (
+		failed = make(map[string]error)
+		added  = make([]*MessageItem[T], 0, len(msgs))
+		// 移除最晚的任务时需要在堆中比较，批内已接受的任务须立即入堆，否则无法被选为移除对象
+		eager = hd.capacity > 0 && hd.full == EvictFurthest
+	)
+	for _, msg := range msgs {
+		msg.resolve(hd.clock.Now())
+		if err := hd.reserve(msg, false); err != nil {
+			failed[msg.id()] = err
+			continue
+		}
+		if _, ok := hd.m[msg.id()]; ok {
+			hd.stats.onDuplicate(msg.id())
+			failed[msg.id()] = RepeatError
+			continue
+		}
+		hd.stats.onAdd(msg.id())
+		hd.seq++
+		msg.seq = hd.seq
+		hd.m[msg.id()] = msg
+		if eager {
+			hd.heap.Push(msg)
+			continue
+		}
+		added = append(added, msg)
+	}
+	if hd.rebuildCheaper(len(added)) {
+		hd.rebuild()
+	} else {
+		for _, msg := range added {
+			hd.heap.Push(msg)
+		}
+	}
+	hd.resetTimer()
+	return batchResult(failed)
+}
+
+// DeleteBatch 批量移除任务，只加锁一次、重置一次定时器
+//
+// 不存在的key返回的 *BatchError 中记录为KeyError，全部成功时返回nil
+func (hd *HeapDelayQueue[T]) DeleteBatch(keys ...string) error {
+	hd.lock.Lock()
+	defer hd.lock.Unlock()
+	var (
+		failed  = make(map[string]error)
+		removed = make([]*MessageItem[T], 0, len(keys))
+	)
+	for _, key := range keys {
+		msg, ok := hd.m[key]
+		if !ok {
+			failed[key] = KeyError
+			continue
+		}
+		delete(hd.m, key)
+		hd.stats.onDelete(key)
+		removed = append(removed, msg)
+	}
+	hd.space.Broadcast()
+	if hd.rebuildCheaper(len(removed)) {
+		for _, msg := range removed {
+			msg.index = -1
+		}
+		hd.rebuild()
+	} else {
+		for _, msg := range removed {
+			hd.heap.Delete(msg.index)
+		}
+	}
+	hd.resetTimer()
+	return batchResult(failed)
+}
+
+// rebuildCheaper 变更k个元素时，整体重新建堆(O(n))是否比逐个调整(O(klog2n))更快
+func (hd *HeapDelayQueue[T]) rebuildCheaper(k int) bool {
+	return k*bits.Len(uint(len(hd.m))) > len(hd.m)
+}
+
+// rebuild 以哈希表中的全部任务重新建堆
+func (hd *HeapDelayQueue[T]) rebuild() {
+	data := make([]*MessageItem[T], 0, len(hd.m))
+	for _, msg := range hd.m {
+		data = append(data, msg)
+	}
+	hd.heap.CreateFromSlice(data, false, heapLess[T])
+}
+
+func batchResult(failed map[string]error) error {
+	if len(failed) == 0 {
+		return nil
+	}
+	return &BatchError{Errors: failed}
+}
@@ -0,0 +1,84 @@
+package delayQueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func Test_AddDeleteBatch(t *testing.T) {
+	hd := NewDelayQe[int]()
+	defer hd.Close()
+	now := time.Now()
+	_ = hd.Add(NewMessageItem("k0", 0, now.Add(time.Hour)))
+
+	// 大批量走整体建堆，小批量走逐个入堆，两种路径都需保证触发顺序
+	msgs := make([]*MessageItem[int], 0, 1000)
+	for i := 999; i >= 1; i-- {
+		msgs = append(msgs, NewMessageItem(fmt.Sprintf("k%d", i), i, now.Add(time.Duration(i)*time.Millisecond)))
+	}
+	msgs = append(msgs, NewMessageItem("k0", -1, now))
+	err := hd.AddBatch(msgs...)
+	var be *BatchError
+	if !errors.As(err, &be) || len(be.Errors) != 1 || !errors.Is(be.Errors["k0"], RepeatError) || !errors.Is(err, RepeatError) {
+		t.Fatalf("expect k0 repeat got %v", err)
+	}
+	if err = hd.AddBatch(NewMessageItem("small", -2, now.Add(-time.Second))); err != nil {
+		t.Fatal(err)
+	}
+	if hd.Len() != 1001 || hd.Peek().Key() != "small" {
+		t.Fatalf("unexpected len %d peek %s", hd.Len(), hd.Peek().Key())
+	}
+
+	keys := []string{"missing", "small"}
+	for i := 2; i <= 999; i += 2 {
+		keys = append(keys, fmt.Sprintf("k%d", i))
+	}
+	err = hd.DeleteBatch(keys...)
+	if !errors.As(err, &be) || len(be.Errors) != 1 || !errors.Is(be.Errors["missing"], KeyError) {
+		t.Fatalf("expect missing key error got %v", err)
+	}
+	if err = hd.DeleteBatch("k999"); err != nil {
+		t.Fatal(err)
+	}
+	if hd.Len() != 500 {
+		t.Fatalf("expect 500 left got %d", hd.Len())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	for i := 1; i < 999; i += 2 {
+		msg, err := hd.Watch(ctx)
+		if err != nil || msg.Content() != i {
+			t.Fatalf("expect %d got %v %v", i, msg, err)
+		}
+	}
+	if hd.Peek().Key() != "k0" {
+		t.Fatalf("expect k0 left got %s", hd.Peek().Key())
+	}
+}
+
+func Benchmark_AddBatch(b *testing.B) {
+	now := time.Now()
+	msgs := make([]*MessageItem[int], 10000)
+	b.Run("add", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			hd := NewDelayQe[int]()
+			for i := range msgs {
+				_ = hd.Add(NewMessageItem(fmt.Sprint(i), i, now.Add(time.Duration(len(msgs)-i)*time.Second)))
+			}
+			hd.Close()
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			hd := NewDelayQe[int]()
+			for i := range msgs {
+				msgs[i] = NewMessageItem(fmt.Sprint(i), i, now.Add(time.Duration(len(msgs)-i)*time.Second))
+			}
+			_ = hd.AddBatch(msgs...)
+			hd.Close()
+		}
+	})
+}
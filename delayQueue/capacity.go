@@ -0,0 +1,91 @@
+package delayQueue
+
+// Pause 暂停触发，期间仍可添加、删除任务，阻塞中的Watch继续等待直到Resume
+func (hd *HeapDelayQueue[T]) Pause() {
+	hd.lock.Lock()
+	defer hd.lock.Unlock()
+	hd.paused = true
+	hd.timer.Stop()
+}
+
+// Resume 恢复触发，暂停期间已过期的任务按触发顺序立即触发
+func (hd *HeapDelayQueue[T]) Resume() {
+	hd.lock.Lock()
+	defer hd.lock.Unlock()
+	hd.paused = false
+	hd.resetTimer()
+}
+
+// Paused 是否已暂停
+func (hd *HeapDelayQueue[T]) Paused() bool {
+	hd.lock.RLock()
+	defer hd.lock.RUnlock()
+	return hd.paused
+}
+
+// tryAdd 添加任务，达到容量上限时不阻塞，BlockWhenFull按RejectWhenFull处理
+func (hd *HeapDelayQueue[T]) tryAdd(msg *MessageItem[T]) error {
+	hd.lock.Lock()
+	defer hd.lock.Unlock()
+	msg.resolve(hd.clock.Now())
+	if err := hd.reserve(msg, false); err != nil {
+		return err
+	}
+	return hd.add(msg)
+}
+
+// reserve 达到容量上限时按策略为新任务腾出空位，key重复的任务交由add返回RepeatError
+//
+// @param wait BlockWhenFull策略下是否阻塞等待，为false时按RejectWhenFull处理
+func (hd *HeapDelayQueue[T]) reserve(msg *MessageItem[T], wait bool) error {
+	if hd.capacity <= 0 {
+		return nil
+	}
+	for len(hd.m) >= hd.capacity {
+		if _, ok := hd.m[msg.id()]; ok {
+			return nil
+		}
+		switch {
+		case hd.full == BlockWhenFull && wait:
+			select {
+			case <-hd.done:
+				return ClosedError
+			default:
+			}
+			hd.space.Wait()
+		case hd.full == EvictFurthest:
+			furthest := hd.furthest()
+			cand := msg.clone() // 按入队后的序号比较，同一时间的任务先入队的先触发
+			cand.seq = hd.seq + 1
+			if furthest == nil || !cand.before(furthest) {
+				return FullError
+			}
+			_ = hd.delete(furthest.id())
+			hd.stats.evicted++
+			if hd.onEvict != nil {
+				hd.onEvict(furthest)
+			}
+		default:
+			return FullError
+		}
+	}
+	return nil
+}
+
+// restore 加入恢复的任务，不受容量上限限制，避免调小容量后无法恢复
+func (hd *HeapDelayQueue[T]) restore(msg *MessageItem[T]) error {
+	hd.lock.Lock()
+	defer hd.lock.Unlock()
+	return hd.add(msg)
+}
+
+// furthest 触发时间最晚的任务，只需遍历堆的叶子节点，复杂度O(n/2)
+func (hd *HeapDelayQueue[T]) furthest() *MessageItem[T] {
+	var res *MessageItem[T]
+	for i := hd.heap.Len() / 2; i < hd.heap.Len(); i++ {
+		if msg := hd.heap.Get(i); res == nil || res.before(msg) {
+			res = msg
+		}
+	}
+	return res
+}
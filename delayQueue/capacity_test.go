@@ -0,0 +1,104 @@
+package delayQueue
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_Capacity(t *testing.T) {
+	now := time.Now()
+	t.Run("reject", func(t *testing.T) {
+		hd := NewDelayQe[int](WithCapacity(2, RejectWhenFull))
+		defer hd.Close()
+		_ = hd.Add(NewMessageItem("k1", 1, now.Add(time.Hour)))
+		_ = hd.Add(NewMessageItem("k2", 2, now.Add(time.Hour)))
+		if err := hd.Add(NewMessageItem("k3", 3, now)); !errors.Is(err, FullError) {
+			t.Fatalf("expect FullError got %v", err)
+		}
+		if err := hd.Add(NewMessageItem("k1", 1, now)); !errors.Is(err, RepeatError) {
+			t.Fatalf("expect RepeatError got %v", err)
+		}
+		if err := hd.AddBatch(NewMessageItem("k3", 3, now)); !errors.Is(err, FullError) {
+			t.Fatalf("expect FullError got %v", err)
+		}
+	})
+	t.Run("evict", func(t *testing.T) {
+		hd := NewDelayQe[int](WithCapacity(3, EvictFurthest))
+		defer hd.Close()
+		for i := 1; i <= 3; i++ {
+			_ = hd.Add(NewMessageItem(string(rune('0'+i)), i, now.Add(time.Duration(i)*time.Hour)))
+		}
+		if err := hd.Add(NewMessageItem("late", 4, now.Add(3*time.Hour))); !errors.Is(err, FullError) {
+			t.Fatalf("expect FullError for furthest message got %v", err)
+		}
+		if err := hd.Add(NewMessageItem("early", 0, now)); err != nil {
+			t.Fatal(err)
+		}
+		if msg, _ := hd.Search("3"); msg != nil || hd.Len() != 3 || hd.Stats().Evicted != 1 {
+			t.Fatalf("expect 3 evicted, len %d", hd.Len())
+		}
+	})
+	t.Run("evict batch", func(t *testing.T) {
+		hd := NewDelayQe[int](WithCapacity(2, EvictFurthest))
+		defer hd.Close()
+		_ = hd.Add(NewMessageItem("a", 1, now.Add(time.Second)))
+		// 批内先加入的c比d更晚，应移除c保留d
+		if err := hd.AddBatch(NewMessageItem("c", 3, now.Add(50*time.Second)), NewMessageItem("d", 4, now.Add(40*time.Second))); err != nil {
+			t.Fatal(err)
+		}
+		if c, _ := hd.Search("c"); c != nil || hd.Len() != 2 || hd.Stats().Evicted != 1 {
+			t.Fatalf("expect c evicted, len %d", hd.Len())
+		}
+		if msg := hd.Peek(); msg.Key() != "a" {
+			t.Fatalf("expect a on top got %s", msg.Key())
+		}
+		if err := hd.Delete("d"); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("upsert", func(t *testing.T) {
+		for _, policy := range []CapacityPolicy{RejectWhenFull, BlockWhenFull} {
+			hd := NewDelayQe[int](WithCapacity(1, policy))
+			_ = hd.Add(NewMessageItem("k1", 1, now.Add(time.Hour)))
+			// 替换已有key不占用新的容量，添加新key不阻塞
+			if err := hd.Upsert(NewMessageItem("k1", 2, now.Add(2*time.Hour))); err != nil {
+				t.Fatal(err)
+			}
+			if err := hd.Upsert(NewMessageItem("k2", 3, now)); !errors.Is(err, FullError) || hd.Len() != 1 {
+				t.Fatalf("policy %d: expect FullError got %v len %d", policy, err, hd.Len())
+			}
+			hd.Close()
+		}
+		hd := NewDelayQe[int](WithCapacity(1, EvictFurthest))
+		defer hd.Close()
+		_ = hd.Add(NewMessageItem("k1", 1, now.Add(time.Hour)))
+		if err := hd.Upsert(NewMessageItem("k2", 2, now)); err != nil {
+			t.Fatal(err)
+		}
+		if msg, _ := hd.Search("k1"); msg != nil || hd.Len() != 1 || hd.Stats().Evicted != 1 {
+			t.Fatalf("expect k1 evicted, len %d", hd.Len())
+		}
+	})
+	t.Run("block", func(t *testing.T) {
+		hd := NewDelayQe[int](WithCapacity(1, BlockWhenFull))
+		_ = hd.Add(NewMessageItem("k1", 1, now.Add(time.Hour)))
+		added := make(chan error)
+		go func() { added <- hd.Add(NewMessageItem("k2", 2, now)) }()
+		select {
+		case err := <-added:
+			t.Fatalf("expect Add blocked got %v", err)
+		case <-time.After(100 * time.Millisecond):
+		}
+		_ = hd.Delete("k1")
+		if err := <-added; err != nil || hd.Len() != 1 {
+			t.Fatalf("expect k2 added got %v", err)
+		}
+		go func() { added <- hd.Add(NewMessageItem("k3", 3, now)) }()
+		time.Sleep(50 * time.Millisecond)
+		hd.Close()
+		if err := <-added; !errors.Is(err, ClosedError) {
+			t.Fatalf("expect ClosedError got %v", err)
+		}
+	})
+}
@@ -0,0 +1,143 @@
+// Package clocktest 提供可手动推进的时钟，用于确定性地测试延迟队列的触发顺序，无需真实等待
+package clocktest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"git.woa.com/kf_cdms/go-public/delayQueue"
+)
+
+var _ delayQueue.Clock = (*FakeClock)(nil)
+
+// FakeClock 手动时钟，时间只在调用 Advance/Set 时前进。并发安全
+type FakeClock struct {
+	now    time.Time
+	timers []*fakeTimer // 等待触发的定时器
+	lock   sync.Mutex
+}
+
+// NewFakeClock 以指定时间初始化手动时钟
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now 当前时间
+func (c *FakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+// NewTimer 创建定时器，d<=0时立即触发
+func (c *FakeClock) NewTimer(d time.Duration) delayQueue.Timer {
+	t := &fakeTimer{clock: c, c: make(chan time.Time, 1)}
+	t.Reset(d)
+	return t
+}
+
+// AfterFunc 创建定时器，到期时在 Advance/Set 的调用协程中同步执行f
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) delayQueue.Timer {
+	t := &fakeTimer{clock: c, f: f}
+	t.Reset(d)
+	return t
+}
+
+// Advance 将时间推进d，并按到期时间依次触发期间到期的定时器
+func (c *FakeClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set 将时间设置为t(早于当前时间时忽略)，并按到期时间依次触发期间到期的定时器
+func (c *FakeClock) Set(t time.Time) {
+	c.lock.Lock()
+	if t.After(c.now) {
+		c.now = t
+	}
+	due := c.expire()
+	c.lock.Unlock()
+	for _, timer := range due {
+		timer.fire(t)
+	}
+}
+
+// Timers 等待触发的定时器数量
+func (c *FakeClock) Timers() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.timers)
+}
+
+// expire 取出已到期的定时器，按到期时间排序
+func (c *FakeClock) expire() []*fakeTimer {
+	var due, pending []*fakeTimer
+	for _, t := range c.timers {
+		if t.when.After(c.now) {
+			pending = append(pending, t)
+		} else {
+			due = append(due, t)
+		}
+	}
+	c.timers = pending
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].when.Before(due[j].when)
+	})
+	return due
+}
+
+// remove 移除等待中的定时器，返回其是否处于等待状态
+func (c *FakeClock) remove(t *fakeTimer) bool {
+	for i, timer := range c.timers {
+		if timer == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// fakeTimer 手动时钟的定时器
+type fakeTimer struct {
+	clock *FakeClock
+	when  time.Time
+	c     chan time.Time
+	f     func()
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.lock.Lock()
+	defer t.clock.lock.Unlock()
+	return t.clock.remove(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	c := t.clock
+	c.lock.Lock()
+	active := c.remove(t)
+	t.when = c.now.Add(d)
+	c.timers = append(c.timers, t)
+	due := c.expire()
+	now := c.now
+	c.lock.Unlock()
+	for _, timer := range due {
+		timer.fire(now)
+	}
+	return active
+}
+
+// fire 触发定时器：与 time.Timer 一致，通道已满时丢弃本次触发
+func (t *fakeTimer) fire(now time.Time) {
+	if t.f != nil {
+		t.f()
+		return
+	}
+	select {
+	case t.c <- now:
+	default:
+	}
+}
@@ -0,0 +1,146 @@
+package delayQueue
+
+import (
+	"context"
+	"time"
+)
+
+// DelayQueue 延迟队列通用接口，屏蔽具体的底层实现
+type DelayQueue[T messageTyps] interface {
+	// Add 添加任务，消息key重复时返回RepeatError
+	Add(msg *MessageItem[T]) error
+	// Delete 移除任务，消息key不存在时返回KeyError
+	Delete(key string) error
+	// Search 查询任务，不存在时返回nil
+	Search(key string) (*MessageItem[T], error)
+	// Peek 提取最近的一个任务详情，但并不出队
+	Peek() *MessageItem[T]
+	// Watch 阻塞直到最早的任务到期
+	Watch(ctx context.Context) (*MessageItem[T], error)
+	// Len 待触发的任务数量
+	Len() int
+	// Close 关闭队列，阻塞中的Watch将返回ClosedError
+	Close()
+}
+
+var (
+	_ DelayQueue[any] = (*HeapDelayQueue[any])(nil)
+	_ DelayQueue[any] = (*TimingWheelDelayQueue[any])(nil)
+	_ DelayQueue[any] = (*PersistentDelayQueue[any])(nil)
+)
+
+// Backend 延迟队列底层实现
+type Backend int
+
+const (
+	HeapBackend        Backend = iota // 最小堆，默认
+	TimingWheelBackend                // 多层时间轮
+)
+
+// CapacityPolicy 队列达到容量上限时Add的处理策略
+type CapacityPolicy int
+
+const (
+	RejectWhenFull CapacityPolicy = iota // 拒绝添加，返回FullError(默认)
+	BlockWhenFull                        // 阻塞直到有消息触发或被删除，队列关闭时返回ClosedError
+	EvictFurthest                        // 移除触发时间最晚的消息，新消息比它还晚时返回FullError
+)
+
+// Option 延迟队列配置项
+type Option func(o *options)
+
+type options struct {
+	backend       Backend
+	snapshotEvery int
+	visibility    time.Duration
+	nackBackoff   func(attempts int) time.Duration
+	metrics       MetricsHook
+	clock         Clock
+	tenantLimit   int
+	capacity      int
+	full          CapacityPolicy
+}
+
+// WithBackend 指定延迟队列底层实现
+func WithBackend(backend Backend) Option {
+	return func(o *options) {
+		o.backend = backend
+	}
+}
+
+// WithSnapshotEvery 持久化队列每写入n条日志生成一次快照，n<=0时仅在Close或手动调用Snapshot时生成
+func WithSnapshotEvery(n int) Option {
+	return func(o *options) {
+		o.snapshotEvery = n
+	}
+}
+
+// WithVisibilityTimeout 确认模式下消息投递后的不可见时长，超时未确认将被重投，默认30秒
+func WithVisibilityTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.visibility = d
+	}
+}
+
+// WithNackBackoff 确认模式下Nack后的重投延迟，默认 ExponentialBackoff(time.Second, 5*time.Minute)
+func WithNackBackoff(backoff func(attempts int) time.Duration) Option {
+	return func(o *options) {
+		o.nackBackoff = backoff
+	}
+}
+
+// WithMetrics 设置指标回调(目前仅 HeapDelayQueue 支持)
+func WithMetrics(hook MetricsHook) Option {
+	return func(o *options) {
+		o.metrics = hook
+	}
+}
+
+// WithClock 指定队列使用的时钟，默认为系统时钟，测试时可传入 clocktest.FakeClock 手动推进时间
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// WithTenantLimit 多租户队列中每个租户默认的待触发消息数上限，<=0表示不限，可通过 Tenant.SetLimit 单独调整
+func WithTenantLimit(n int) Option {
+	return func(o *options) {
+		o.tenantLimit = n
+	}
+}
+
+// WithCapacity 限制待触发的消息数量(目前仅 HeapDelayQueue 支持)，n<=0表示不限
+//
+// @param policy 达到上限时Add的处理策略
+func WithCapacity(n int, policy CapacityPolicy) Option {
+	return func(o *options) {
+		o.capacity = n
+		o.full = policy
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		backend:       HeapBackend,
+		snapshotEvery: 10000,
+		visibility:    30 * time.Second,
+		nackBackoff:   ExponentialBackoff(time.Second, 5*time.Minute),
+		clock:         realClock{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// New 按配置初始化延迟队列，默认使用最小堆实现
+func New[T messageTyps](opts ...Option) DelayQueue[T] {
+	o := newOptions(opts)
+	switch o.backend {
+	case TimingWheelBackend:
+		return NewTimingWheelDelayQe[T](opts...)
+	default:
+		return NewDelayQe[T](opts...)
+	}
+}
@@ -0,0 +1,205 @@
+package delayQueue_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"git.woa.com/kf_cdms/go-public/delayQueue"
+	"git.woa.com/kf_cdms/go-public/delayQueue/clocktest"
+)
+
+// 所有底层实现都需通过的一致性测试
+var backends = map[string]delayQueue.Backend{
+	"heap":        delayQueue.HeapBackend,
+	"timingWheel": delayQueue.TimingWheelBackend,
+}
+
+// newFakeClock 测试使用的手动时钟，起始于整秒，与时间轮刻度对齐
+func newFakeClock() *clocktest.FakeClock {
+	return clocktest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local))
+}
+
+// advance 等待队列设置好定时器后再推进时钟
+//
+// 时间轮在推进协程中异步重置定时器，在此之前推进时钟会错过该次触发。调用前队列中需有未到期的消息或时间轮正在运行
+func advance(clock *clocktest.FakeClock, d time.Duration) {
+	for clock.Timers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(d)
+}
+
+// expectNone 校验队列当前没有已到期的消息
+func expectNone[T any](t *testing.T, q delayQueue.DelayQueue[T]) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if msg, err := q.Watch(ctx); !errors.Is(err, delayQueue.CtxDoneError) {
+		t.Fatalf("expect nothing due got %v %v", msg, err)
+	}
+}
+
+func Test_DelayQueueConformance(t *testing.T) {
+	for name, backend := range backends {
+		backend := backend
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			t.Run("crud", func(t *testing.T) {
+				clock := newFakeClock()
+				conformanceCrud(t, clock, delayQueue.New[string](delayQueue.WithBackend(backend), delayQueue.WithClock(clock)))
+			})
+			t.Run("order", func(t *testing.T) {
+				clock := newFakeClock()
+				conformanceOrder(t, clock, delayQueue.New[string](delayQueue.WithBackend(backend), delayQueue.WithClock(clock)))
+			})
+			t.Run("close", func(t *testing.T) {
+				clock := newFakeClock()
+				conformanceClose(t, clock, delayQueue.New[string](delayQueue.WithBackend(backend), delayQueue.WithClock(clock)))
+			})
+		})
+	}
+}
+
+func conformanceCrud(t *testing.T, clock delayQueue.Clock, q delayQueue.DelayQueue[string]) {
+	defer q.Close()
+	now := clock.Now()
+	if q.Peek() != nil {
+		t.Fatal("expect nil peek on empty queue")
+	}
+	if err := q.Add(delayQueue.NewMessageItem("k1", "v1", now.Add(time.Hour))); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Add(delayQueue.NewMessageItem("k2", "v2", now.Add(time.Minute))); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Add(delayQueue.NewMessageItem("k1", "v3", now.Add(time.Second))); !errors.Is(err, delayQueue.RepeatError) {
+		t.Fatalf("expect RepeatError got %v", err)
+	}
+	if q.Len() != 2 {
+		t.Fatalf("expect len 2 got %d", q.Len())
+	}
+	if msg := q.Peek(); msg == nil || msg.Key() != "k2" {
+		t.Fatalf("expect peek k2 got %v", msg)
+	}
+	if msg, err := q.Search("k1"); err != nil || msg == nil || msg.Content() != "v1" {
+		t.Fatalf("expect search k1 got %v %v", msg, err)
+	}
+	if msg, _ := q.Search("k3"); msg != nil {
+		t.Fatalf("expect search k3 nil got %v", msg)
+	}
+	if err := q.Delete("k2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Delete("k2"); !errors.Is(err, delayQueue.KeyError) {
+		t.Fatalf("expect KeyError got %v", err)
+	}
+	if msg := q.Peek(); msg == nil || msg.Key() != "k1" {
+		t.Fatalf("expect peek k1 got %v", msg)
+	}
+	if q.Len() != 1 {
+		t.Fatalf("expect len 1 got %d", q.Len())
+	}
+}
+
+func conformanceOrder(t *testing.T, clock *clocktest.FakeClock, q delayQueue.DelayQueue[string]) {
+	defer q.Close()
+	now := clock.Now()
+	_ = q.Add(delayQueue.NewMessageItem("k3", "v3", now.Add(2*time.Second)))
+	_ = q.Add(delayQueue.NewMessageItem("k1", "v1", now.Add(-time.Second)))
+	_ = q.Add(delayQueue.NewMessageItem("k2", "v2", now.Add(time.Second)))
+	_ = q.Add(delayQueue.NewMessageItem("k4", "v4", now.Add(time.Hour)))
+	_ = q.Delete("k4")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// 每推进1秒恰好触发一条消息
+	for i, expect := range []string{"k1", "k2", "k3"} {
+		if i > 0 {
+			advance(clock, time.Second)
+		}
+		msg, err := q.Watch(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.Key() != expect {
+			t.Fatalf("expect %s got %s", expect, msg.Key())
+		}
+		if clock.Now().Before(msg.Time()) {
+			t.Fatalf("%s fired before deadline", expect)
+		}
+		expectNone(t, q)
+	}
+	if q.Len() != 0 {
+		t.Fatalf("expect empty queue got %d", q.Len())
+	}
+}
+
+func conformanceClose(t *testing.T, clock delayQueue.Clock, q delayQueue.DelayQueue[string]) {
+	_ = q.Add(delayQueue.NewMessageItem("k1", "v1", clock.Now().Add(time.Hour)))
+	go q.Close()
+	if _, err := q.Watch(context.Background()); !errors.Is(err, delayQueue.ClosedError) {
+		t.Fatalf("expect ClosedError got %v", err)
+	}
+}
+
+func Test_RecurringMessage(t *testing.T) {
+	for name, backend := range backends {
+		clock := newFakeClock()
+		q := delayQueue.New[string](delayQueue.WithBackend(backend), delayQueue.WithClock(clock))
+		start := clock.Now()
+		_ = q.Add(delayQueue.NewMessageItem("job", "tick", start, delayQueue.WithSchedule(delayQueue.Every(time.Second)), delayQueue.WithMaxCount(3)))
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		for occurrence := 1; occurrence <= 3; occurrence++ {
+			if occurrence > 1 {
+				advance(clock, time.Second)
+			}
+			msg, err := q.Watch(ctx)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if msg.Occurrence() != occurrence || msg.Key() != "job" {
+				t.Fatalf("%s: expect occurrence %d got %d", name, occurrence, msg.Occurrence())
+			}
+			if expect := start.Add(time.Duration(occurrence-1) * time.Second); !msg.Time().Equal(expect) {
+				t.Fatalf("%s: expect occurrence %d scheduled at %s got %s", name, occurrence, expect, msg.Time())
+			}
+		}
+		if q.Len() != 0 {
+			t.Fatalf("%s: expect no more occurrence after max count, len %d", name, q.Len())
+		}
+		cancel()
+		q.Close()
+	}
+}
+
+func Test_TimingWheelWatch(t *testing.T) {
+	clock := newFakeClock()
+	tw := delayQueue.NewTimingWheelDelayQe[string](delayQueue.WithClock(clock))
+	defer tw.Close()
+	now := clock.Now()
+	_ = tw.Add(delayQueue.NewMessageItem("late", "late", now.Add(2*time.Second)))
+	_ = tw.Add(delayQueue.NewMessageItem("early", "early", now.Add(time.Second)))
+	_ = tw.Add(delayQueue.NewMessageItem("past", "past", now.Add(-time.Second)))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for i, expect := range []string{"past", "early", "late"} {
+		if i > 0 {
+			advance(clock, time.Second)
+		}
+		msg, err := tw.Watch(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.Content() != expect {
+			t.Fatalf("expect %s got %s", expect, msg.Content())
+		}
+		if clock.Now().Before(msg.Time()) {
+			t.Fatalf("%s fired before deadline", expect)
+		}
+	}
+	tw.Close()
+	if _, err := tw.Watch(ctx); !errors.Is(err, delayQueue.ClosedError) {
+		t.Fatalf("expect ClosedError got %v", err)
+	}
+}
@@ -1,5 +1,20 @@
 // Package delayQueue 延迟队列实现
 //
-// 使用最小堆 + 哈希表实现。这种延迟队列的实现对事件总数量敏感（堆排序复杂度是Nlog2n），对事件延迟时间不敏感。
-// 如果有大量、高频、重复性事件，可以考虑使用时间轮算法实现.
+// HeapDelayQueue 使用最小堆 + 哈希表实现。这种延迟队列的实现对事件总数量敏感（堆排序复杂度是Nlog2n），对事件延迟时间不敏感。
+//
+// TimingWheelDelayQueue 使用秒/分/时/天四层时间轮实现，插入和删除均为O(1)，触发精度为1秒，
+// 适用于大量、高频、重复性事件。
+//
+// 两种实现均满足 DelayQueue 接口，可通过 New(WithBackend(...)) 按需切换，调用方无需改动。
+//
+// PersistentDelayQueue 在任意实现之上增加预写日志与快照，进程重启后可恢复未触发的消息。
+// TenantDelayQueue 让多个租户共享一个队列，key只需在租户内唯一，并支持按租户限流、统计、暂停与清除。
+// AckDelayQueue 提供至少一次投递的确认模式(Ack/Nack + 可见性超时)。
+// HeapDelayQueue.Run 以单个分发协程 + 多个worker的方式并发处理到期消息，多个协程直接并发调用Watch会争抢同一个timer。
+// HeapDelayQueue 支持 Pause/Resume 暂停与恢复触发，以及通过 WithCapacity 限制容量(拒绝、阻塞或移除最晚的消息)。
+// Subscribe 将到期消息写入通道，便于在事件循环中与其他通道一起select。
+//
+// 队列通过 Clock 获取时间与创建定时器，测试时可用 WithClock 注入 clocktest.FakeClock，手动推进时间而无需真实等待。
+//
+// 通过 NewMessageItem 的 WithSchedule 选项可创建按固定间隔(Every)或cron表达式(ParseCron)重复触发的周期消息。
 package delayQueue
@@ -1,8 +1,48 @@
 package delayQueue
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
 
-var RepeatError = errors.New("message key has repeat") // 消息key重复
-var KeyError = errors.New("invalid key")               // 无效的消息key
-var CtxDoneError = errors.New("context done quit")     // ctx退出
-var EmptyQueue = errors.New("empty queue")             // 空队列
+var RepeatError = errors.New("message key has repeat")            // 消息key重复
+var KeyError = errors.New("invalid key")                          // 无效的消息key
+var CtxDoneError = errors.New("context done quit")                // ctx退出
+var EmptyQueue = errors.New("empty queue")                        // 空队列
+var ClosedError = errors.New("queue closed")                      // 队列已关闭
+var LeaseError = errors.New("lease expired")                      // 租约已失效(已被确认或超时重投)
+var PanicError = errors.New("handler panic")                      // 处理函数panic
+var RunningError = errors.New("queue is already running")         // 队列已在Run中
+var FullError = errors.New("queue is full")                       // 队列已达容量上限
+var TenantFullError = errors.New("tenant capacity exceeded")      // 租户待触发消息数已达上限
+var ScheduleError = errors.New("recurring message not supported") // 不支持周期消息
+
+// BatchError 批量操作中部分消息失败，Errors记录每个失败key对应的错误
+//
+// 可通过 errors.Is(err, RepeatError) 判断是否包含某类错误
+type BatchError struct {
+	Errors map[string]error
+}
+
+func (e *BatchError) Error() string {
+	keys := make([]string, 0, len(e.Errors))
+	for key := range e.Errors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s: %v", key, e.Errors[key]))
+	}
+	return fmt.Sprintf("%d of batch failed: %s", len(keys), strings.Join(parts, "; "))
+}
+
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+	return errs
+}
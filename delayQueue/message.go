@@ -1,24 +1,189 @@
 package delayQueue
 
 import (
+	"strings"
 	"time"
 )
 
 // MessageItem 每条消息的结构
 type MessageItem[T any] struct {
-	key     string    // 消息key标识,唯一不可重复
-	content T         // 消息内容
-	sec     time.Time // 过期时间
+	key        string    // 消息key标识,唯一不可重复
+	content    T         // 消息内容
+	sec        time.Time // 过期时间
+	attempts   int       // 投递次数，在确认模式(AckDelayQueue)或Run重试时累加
+	schedule   Schedule  // 周期规则，为nil时为一次性消息
+	until      time.Time // 周期消息的结束时间，零值表示不限
+	maxCount   int       // 周期消息的最大触发次数，<=0表示不限
+	occurrence int       // 周期消息当前是第几次触发(从1开始)
+	index      int       // 在最小堆中的下标，由堆维护
+	priority   int       // 优先级，过期时间相同时数值越大越先触发
+	seq        uint64    // 入队序号，过期时间与优先级都相同时先入队先触发
+	tenant     string    // 所属租户，key只需在同一租户内唯一
 }
 
-func NewMessageItem[T any](key string, content T, sec time.Time) *MessageItem[T] {
+// MessageOption 消息配置项
+type MessageOption func(o *messageOptions)
+
+type messageOptions struct {
+	schedule Schedule
+	until    time.Time
+	maxCount int
+	priority int
+	tenant   string
+}
+
+// WithSchedule 设置周期规则(Every 或 ParseCron)，消息触发后会以同一个key自动加入下一次触发，直到被删除或达到结束条件。
+// 错过的触发(如消费滞后)不会补发，下一次触发时间从当前时间重新计算。
+func WithSchedule(schedule Schedule) MessageOption {
+	return func(o *messageOptions) {
+		o.schedule = schedule
+	}
+}
+
+// WithUntil 周期消息的结束时间，下一次触发晚于该时间时不再入队
+func WithUntil(until time.Time) MessageOption {
+	return func(o *messageOptions) {
+		o.until = until
+	}
+}
+
+// WithMaxCount 周期消息的最大触发次数
+func WithMaxCount(n int) MessageOption {
+	return func(o *messageOptions) {
+		o.maxCount = n
+	}
+}
+
+// WithPriority 设置优先级，过期时间相同的消息按优先级从高到低触发，优先级也相同时按入队顺序触发。默认为0
+func WithPriority(priority int) MessageOption {
+	return func(o *messageOptions) {
+		o.priority = priority
+	}
+}
+
+// WithTenant 设置消息所属租户(命名空间)，不同租户的消息key可以重复，默认为空(默认租户)。
+// 租户消息一般通过 TenantDelayQueue 添加，以便按租户限流、统计、暂停和清除
+func WithTenant(tenant string) MessageOption {
+	return func(o *messageOptions) {
+		o.tenant = tenant
+	}
+}
+
+// NewMessageItem 初始化消息
+//
+// @param sec 过期时间；周期消息为首次触发时间，传零值时在加入队列时由周期规则按队列的时钟计算
+func NewMessageItem[T any](key string, content T, sec time.Time, opts ...MessageOption) *MessageItem[T] {
+	o := &messageOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
 	return &MessageItem[T]{
-		key:     key,
-		content: content,
-		sec:     sec,
+		key:        key,
+		content:    content,
+		sec:        sec,
+		schedule:   o.schedule,
+		until:      o.until,
+		maxCount:   o.maxCount,
+		occurrence: 1,
+		priority:   o.priority,
+		tenant:     o.tenant,
 	}
 }
 
 func (m *MessageItem[T]) Content() T {
 	return m.content
 }
+
+// Key 消息key
+func (m *MessageItem[T]) Key() string {
+	return m.key
+}
+
+// Tenant 消息所属租户
+func (m *MessageItem[T]) Tenant() string {
+	return m.tenant
+}
+
+// id 消息在队列中的唯一标识，默认租户即为key
+func (m *MessageItem[T]) id() string {
+	return scopedKey(m.tenant, m.key)
+}
+
+// scopedKey 租户内的key在队列中的唯一标识，默认租户的key不能包含\x00
+func scopedKey(tenant, key string) string {
+	if tenant == "" {
+		return key
+	}
+	return tenant + "\x00" + key
+}
+
+// SplitKey 将队列中的唯一标识(如 MetricsHook 收到的key)拆分为租户与租户内的key
+func SplitKey(id string) (tenant, key string) {
+	if i := strings.IndexByte(id, 0); i >= 0 {
+		return id[:i], id[i+1:]
+	}
+	return "", id
+}
+
+// Time 消息过期时间
+func (m *MessageItem[T]) Time() time.Time {
+	return m.sec
+}
+
+// Attempts 投递次数，确认模式下首次投递为1、每次重投加1；Run中为已失败的处理次数
+func (m *MessageItem[T]) Attempts() int {
+	return m.attempts
+}
+
+// Priority 消息优先级
+func (m *MessageItem[T]) Priority() int {
+	return m.priority
+}
+
+// Occurrence 周期消息当前是第几次触发(从1开始)，一次性消息恒为1
+func (m *MessageItem[T]) Occurrence() int {
+	return m.occurrence
+}
+
+// before 触发顺序：先比较过期时间，再比较优先级，最后比较入队序号
+func (m *MessageItem[T]) before(o *MessageItem[T]) bool {
+	if !m.sec.Equal(o.sec) {
+		return m.sec.Before(o.sec)
+	}
+	if m.priority != o.priority {
+		return m.priority > o.priority
+	}
+	return m.seq < o.seq
+}
+
+// resolve 未指定首次触发时间的周期消息，按加入队列时的时间计算首次触发
+func (m *MessageItem[T]) resolve(now time.Time) {
+	if m.sec.IsZero() && m.schedule != nil {
+		m.sec = m.schedule.Next(now)
+	}
+}
+
+// clone 浅拷贝消息，用于重新入队
+func (m *MessageItem[T]) clone() *MessageItem[T] {
+	cp := *m
+	return &cp
+}
+
+// next 计算周期消息的下一次触发，一次性消息或已达到结束条件时返回nil
+func (m *MessageItem[T]) next(now time.Time) *MessageItem[T] {
+	if m.schedule == nil || (m.maxCount > 0 && m.occurrence >= m.maxCount) {
+		return nil
+	}
+	sec := m.schedule.Next(m.sec)
+	if !sec.IsZero() && sec.Before(now) { // 消费滞后，跳过错过的触发
+		sec = m.schedule.Next(now)
+	}
+	if sec.IsZero() || (!m.until.IsZero() && sec.After(m.until)) {
+		return nil
+	}
+	nx := m.clone()
+	nx.sec = sec
+	nx.attempts = 0
+	nx.occurrence++
+	return nx
+}
@@ -0,0 +1,405 @@
+package delayQueue
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Codec 消息内容编解码器，持久化队列通过它将泛型内容T写入磁盘
+type Codec[T any] interface {
+	Encode(v T) ([]byte, error)
+	Decode(data []byte) (T, error)
+}
+
+// JSONCodec 基于encoding/json的编解码器
+type JSONCodec[T any] struct{}
+
+func (JSONCodec[T]) Encode(v T) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec[T]) Decode(data []byte) (T, error) {
+	var v T
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
+
+const (
+	walFileName      = "wal.log"
+	snapshotFileName = "snapshot.log"
+)
+
+// walOp 预写日志操作类型
+type walOp int8
+
+const (
+	walAdd    walOp = iota + 1 // 添加
+	walDelete                  // 删除
+	walFire                    // 触发
+)
+
+// walRecord 预写日志记录，每行一条json
+type walRecord struct {
+	Op         walOp  `json:"op"`
+	Key        string `json:"key"`
+	Tenant     string `json:"tenant,omitempty"`     // 消息所属租户
+	Sec        int64  `json:"sec,omitempty"`        // 过期时间(UnixNano)
+	Data       []byte `json:"data,omitempty"`       // Codec编码后的消息内容
+	Every      int64  `json:"every,omitempty"`      // 周期消息的固定间隔(纳秒)
+	Cron       string `json:"cron,omitempty"`       // 周期消息的cron表达式
+	Until      int64  `json:"until,omitempty"`      // 周期消息的结束时间(UnixNano)
+	MaxCount   int    `json:"maxCount,omitempty"`   // 周期消息的最大触发次数
+	Occurrence int    `json:"occurrence,omitempty"` // 周期消息当前是第几次触发
+	Priority   int    `json:"priority,omitempty"`   // 优先级
+}
+
+// id 记录对应消息在队列中的唯一标识
+func (r walRecord) id() string {
+	return scopedKey(r.Tenant, r.Key)
+}
+
+// PersistentDelayQueue 可持久化的延迟队列
+//
+// Add/Delete/触发 都会追加写入预写日志(WAL)，并按配置周期性生成快照、截断日志。
+// 重启时通过 NewPersistentDelayQe 回放快照与日志恢复未触发的消息，停机期间已过期的消息会在恢复后立即触发。
+// 日志写入操作系统页缓存后即返回，可抵御进程崩溃，不保证机器掉电时不丢失数据。
+// 设置 WithCapacity 时，按EvictFurthest策略移除的消息同样记录到日志；恢复时不受容量上限限制。
+// 周期规则(Every/ParseCron)及其结束条件随消息落盘，恢复后继续按原规则触发；自定义的 Schedule 实现无法落盘，恢复后仅保留其下一次触发。
+type PersistentDelayQueue[T messageTyps] struct {
+	q             DelayQueue[T]              // 底层延迟队列
+	codec         Codec[T]                   // 消息内容编解码器
+	dir           string                     // 数据目录
+	wal           *os.File                   // 预写日志
+	pending       map[string]walRecord       // 未触发消息，用于生成快照
+	items         map[string]*MessageItem[T] // 未触发消息对应的对象，用于识别触发的是否为当前记录的消息
+	ops           int                        // 距上次快照写入的日志条数
+	snapshotEvery int                        // 每写入多少条日志生成一次快照
+	lock          sync.Mutex
+}
+
+// NewPersistentDelayQe 初始化持久化延迟队列，若数据目录中存在历史数据会先回放恢复
+//
+// @param dir 数据目录，不存在时自动创建
+// @param codec 消息内容编解码器
+func NewPersistentDelayQe[T messageTyps](dir string, codec Codec[T], opts ...Option) (*PersistentDelayQueue[T], error) {
+	o := newOptions(opts)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	pq := &PersistentDelayQueue[T]{
+		q:             New[T](opts...),
+		codec:         codec,
+		dir:           dir,
+		pending:       make(map[string]walRecord),
+		items:         make(map[string]*MessageItem[T]),
+		snapshotEvery: o.snapshotEvery,
+	}
+	if hd, ok := pq.q.(*HeapDelayQueue[T]); ok {
+		hd.onEvict = pq.evicted
+	}
+	if err := pq.recover(); err != nil {
+		pq.q.Close()
+		return nil, err
+	}
+	return pq, nil
+}
+
+// Add 添加任务
+func (pq *PersistentDelayQueue[T]) Add(msg *MessageItem[T]) error {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+	if err := pq.q.Add(msg); err != nil {
+		return err
+	}
+	// 加入队列后才能确定周期消息的首次触发时间
+	record, err := pq.record(msg)
+	if err != nil {
+		_ = pq.q.Delete(msg.id())
+		return err
+	}
+	if err = pq.append(record); err != nil {
+		_ = pq.q.Delete(msg.id()) // 日志写入失败，回滚内存中的任务
+		return err
+	}
+	// 日志已落盘，此后快照失败也不再回滚，否则重启后会恢复出调用方认为添加失败的任务
+	pq.pending[msg.id()] = record
+	pq.items[msg.id()] = msg
+	return pq.snapshotIfNeeded()
+}
+
+// evicted 底层队列按EvictFurthest策略移除了消息，在Add持有锁时调用
+func (pq *PersistentDelayQueue[T]) evicted(msg *MessageItem[T]) {
+	if pq.items[msg.id()] != msg {
+		return
+	}
+	delete(pq.pending, msg.id())
+	delete(pq.items, msg.id())
+	// 写入失败时日志中残留的记录会在下一次快照时清除
+	_ = pq.append(walRecord{Op: walDelete, Key: msg.key, Tenant: msg.tenant})
+}
+
+// Delete 移除任务
+func (pq *PersistentDelayQueue[T]) Delete(key string) error {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+	if err := pq.q.Delete(key); err != nil {
+		return err
+	}
+	delete(pq.pending, key)
+	delete(pq.items, key)
+	if err := pq.append(walRecord{Op: walDelete, Key: key}); err != nil {
+		return err
+	}
+	return pq.snapshotIfNeeded()
+}
+
+// Search 查询任务
+func (pq *PersistentDelayQueue[T]) Search(key string) (*MessageItem[T], error) {
+	return pq.q.Search(key)
+}
+
+// Peek 提取最近的一个任务详情，但并不出队
+func (pq *PersistentDelayQueue[T]) Peek() *MessageItem[T] {
+	return pq.q.Peek()
+}
+
+// Len 待触发的任务数量
+func (pq *PersistentDelayQueue[T]) Len() int {
+	return pq.q.Len()
+}
+
+// Watch 监听延迟队列，触发的任务会记录到日志中，恢复时不再重复触发
+//
+// 若进程在任务返回后、触发记录落盘前崩溃，该任务会在恢复后再次触发(至少一次)
+func (pq *PersistentDelayQueue[T]) Watch(ctx context.Context) (*MessageItem[T], error) {
+	msg, err := pq.q.Watch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return msg, pq.fired(msg)
+}
+
+// fired 记录消息已触发，周期消息同时记录其下一次触发
+func (pq *PersistentDelayQueue[T]) fired(msg *MessageItem[T]) error {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+	// 出队后、加锁前同一key可能已被重新Add，此时记录属于新消息，不能删除
+	if pq.items[msg.id()] != msg {
+		return nil
+	}
+	delete(pq.pending, msg.id())
+	delete(pq.items, msg.id())
+	if err := pq.append(walRecord{Op: walFire, Key: msg.key, Tenant: msg.tenant}); err != nil {
+		return err
+	}
+	// 周期消息的下一次触发已由底层队列加入，同样需要落盘
+	if next, _ := pq.q.Search(msg.id()); next != nil {
+		record, err := pq.record(next)
+		if err != nil {
+			return err
+		}
+		if err = pq.append(record); err != nil {
+			return err
+		}
+		pq.pending[next.id()] = record
+		pq.items[next.id()] = next
+	}
+	return pq.snapshotIfNeeded()
+}
+
+// Snapshot 立即生成快照并截断预写日志
+func (pq *PersistentDelayQueue[T]) Snapshot() error {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+	if pq.wal == nil {
+		return ClosedError
+	}
+	return pq.snapshot()
+}
+
+// Close 生成快照并关闭队列
+func (pq *PersistentDelayQueue[T]) Close() {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+	pq.q.Close()
+	if pq.wal == nil {
+		return
+	}
+	_ = pq.snapshot()
+	_ = pq.wal.Close()
+	pq.wal = nil
+}
+
+// record 生成消息的添加记录
+func (pq *PersistentDelayQueue[T]) record(msg *MessageItem[T]) (walRecord, error) {
+	data, err := pq.codec.Encode(msg.content)
+	if err != nil {
+		return walRecord{}, err
+	}
+	record := walRecord{Op: walAdd, Key: msg.key, Tenant: msg.tenant, Sec: msg.sec.UnixNano(), Data: data, Priority: msg.priority}
+	if msg.schedule == nil {
+		return record, nil
+	}
+	switch s := msg.schedule.(type) {
+	case everySchedule:
+		record.Every = int64(s.interval)
+	case *cronSchedule:
+		record.Cron = s.expr
+	}
+	if !msg.until.IsZero() {
+		record.Until = msg.until.UnixNano()
+	}
+	record.MaxCount = msg.maxCount
+	record.Occurrence = msg.occurrence
+	return record, nil
+}
+
+// recover 回放快照与预写日志，并将恢复的任务重新加入队列
+func (pq *PersistentDelayQueue[T]) recover() error {
+	for _, name := range []string{snapshotFileName, walFileName} {
+		if err := pq.replay(filepath.Join(pq.dir, name)); err != nil {
+			return err
+		}
+	}
+	for _, record := range pq.pending {
+		content, err := pq.codec.Decode(record.Data)
+		if err != nil {
+			return err
+		}
+		opts := []MessageOption{WithTenant(record.Tenant), WithPriority(record.Priority), WithMaxCount(record.MaxCount)}
+		if record.Until != 0 {
+			opts = append(opts, WithUntil(time.Unix(0, record.Until)))
+		}
+		switch {
+		case record.Every > 0:
+			opts = append(opts, WithSchedule(Every(time.Duration(record.Every))))
+		case record.Cron != "":
+			schedule, err := ParseCron(record.Cron)
+			if err != nil {
+				return err
+			}
+			opts = append(opts, WithSchedule(schedule))
+		}
+		msg := NewMessageItem(record.Key, content, time.Unix(0, record.Sec), opts...)
+		msg.occurrence = max(record.Occurrence, 1)
+		if err = pq.restore(msg); err != nil {
+			return err
+		}
+		pq.items[msg.id()] = msg
+	}
+	// 将回放结果压缩为新的快照，日志从空文件开始
+	return pq.snapshot()
+}
+
+// restore 将恢复的任务加入底层队列，不受 WithCapacity 的容量上限限制
+func (pq *PersistentDelayQueue[T]) restore(msg *MessageItem[T]) error {
+	if hd, ok := pq.q.(*HeapDelayQueue[T]); ok {
+		return hd.restore(msg)
+	}
+	return pq.q.Add(msg)
+}
+
+// replay 读取日志文件并应用到pending中。只忽略末尾不完整的记录(写入时崩溃)，其余位置的损坏记录返回错误
+func (pq *PersistentDelayQueue[T]) replay(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	var corrupt error // 上一行的解析错误，其后仍有记录时说明不是末尾的残缺记录
+	for line := 1; scanner.Scan(); line++ {
+		if corrupt != nil {
+			return fmt.Errorf("%s line %d: %w", path, line-1, corrupt)
+		}
+		var record walRecord
+		if err = json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			corrupt = err
+			continue
+		}
+		switch record.Op {
+		case walAdd:
+			pq.pending[record.id()] = record
+		case walDelete, walFire:
+			delete(pq.pending, record.id())
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// append 追加一条日志
+func (pq *PersistentDelayQueue[T]) append(record walRecord) error {
+	if pq.wal == nil {
+		return ClosedError
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err = pq.wal.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	pq.ops++
+	return nil
+}
+
+// snapshotIfNeeded 日志条数达到阈值时生成快照，需在pending更新后调用，否则快照会遗漏刚写入的记录
+func (pq *PersistentDelayQueue[T]) snapshotIfNeeded() error {
+	if pq.snapshotEvery > 0 && pq.ops >= pq.snapshotEvery {
+		return pq.snapshot()
+	}
+	return nil
+}
+
+// snapshot 将pending写入临时文件后原子替换快照，再截断预写日志
+func (pq *PersistentDelayQueue[T]) snapshot() error {
+	tmp := filepath.Join(pq.dir, snapshotFileName+".tmp")
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, record := range pq.pending {
+		line, err := json.Marshal(record)
+		if err != nil {
+			_ = f.Close()
+			return err
+		}
+		_, _ = w.Write(append(line, '\n'))
+	}
+	if err = w.Flush(); err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, filepath.Join(pq.dir, snapshotFileName)); err != nil {
+		return err
+	}
+	if pq.wal != nil {
+		_ = pq.wal.Close()
+	}
+	pq.wal, err = os.OpenFile(filepath.Join(pq.dir, walFileName), os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	pq.ops = 0
+	return nil
+}
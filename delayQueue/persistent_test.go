@@ -0,0 +1,267 @@
+package delayQueue
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type reminder struct {
+	Task string `json:"task"`
+}
+
+func Test_PersistentRecover(t *testing.T) {
+	dir := t.TempDir()
+	pq, err := NewPersistentDelayQe[reminder](dir, JSONCodec[reminder]{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	_ = pq.Add(NewMessageItem("fired", reminder{"fired"}, now.Add(-time.Second)))
+	_ = pq.Add(NewMessageItem("overdue", reminder{"overdue"}, now.Add(200*time.Millisecond)))
+	_ = pq.Add(NewMessageItem("deleted", reminder{"deleted"}, now.Add(time.Hour)))
+	_ = pq.Add(NewMessageItem("future", reminder{"future"}, now.Add(time.Hour)))
+	if err = pq.Delete("deleted"); err != nil {
+		t.Fatal(err)
+	}
+	msg, err := pq.Watch(context.Background())
+	if err != nil || msg.Key() != "fired" {
+		t.Fatalf("expect fired got %v %v", msg, err)
+	}
+	// 模拟进程崩溃：不调用Close，并在日志末尾写入半条记录
+	pq.q.Close()
+	f, _ := os.OpenFile(filepath.Join(dir, walFileName), os.O_APPEND|os.O_WRONLY, 0o644)
+	_, _ = f.WriteString(`{"op":1,"key":"torn"`)
+	_ = f.Close()
+	time.Sleep(300 * time.Millisecond)
+
+	recovered, err := NewPersistentDelayQe[reminder](dir, JSONCodec[reminder]{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recovered.Close()
+	if recovered.Len() != 2 {
+		t.Fatalf("expect 2 recovered items got %d", recovered.Len())
+	}
+	if msg, _ = recovered.Search("future"); msg == nil || msg.Content().Task != "future" || !msg.Time().Equal(now.Add(time.Hour)) {
+		t.Fatalf("expect future recovered got %v", msg)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	msg, err = recovered.Watch(ctx)
+	if err != nil || msg.Key() != "overdue" {
+		t.Fatalf("expect overdue fired immediately got %v %v", msg, err)
+	}
+}
+
+func Test_PersistentSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	pq, err := NewPersistentDelayQe[string](dir, JSONCodec[string]{}, WithSnapshotEvery(3), WithBackend(TimingWheelBackend))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for _, key := range []string{"k1", "k2", "k3", "k4"} {
+		_ = pq.Add(NewMessageItem(key, key, now.Add(time.Hour)))
+	}
+	_ = pq.Delete("k2")
+	// 第3条日志触发快照，之后日志中只剩2条
+	if records := countLines(t, filepath.Join(dir, walFileName)); records != 2 {
+		t.Fatalf("expect 2 wal records got %d", records)
+	}
+	pq.Close()
+	if records := countLines(t, filepath.Join(dir, walFileName)); records != 0 {
+		t.Fatalf("expect empty wal after close got %d", records)
+	}
+	reopened, err := NewPersistentDelayQe[string](dir, JSONCodec[string]{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	for _, key := range []string{"k1", "k3", "k4"} {
+		if msg, _ := reopened.Search(key); msg == nil || msg.Content() != key {
+			t.Fatalf("expect %s recovered got %v", key, msg)
+		}
+	}
+	if reopened.Len() != 3 {
+		t.Fatalf("expect 3 items got %d", reopened.Len())
+	}
+}
+
+func countLines(t *testing.T, path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for _, b := range data {
+		if b == '\n' {
+			n++
+		}
+	}
+	return n
+}
+
+func Test_PersistentReAddDuringWatch(t *testing.T) {
+	dir := t.TempDir()
+	pq, err := NewPersistentDelayQe[string](dir, JSONCodec[string]{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = pq.Add(NewMessageItem("job", "old", time.Now().Add(-time.Second)))
+	// 模拟Watch出队后、记录触发前，同一key被重新Add
+	msg, err := pq.q.Watch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = pq.Add(NewMessageItem("job", "new", time.Now().Add(time.Hour))); err != nil {
+		t.Fatal(err)
+	}
+	if err = pq.fired(msg); err != nil {
+		t.Fatal(err)
+	}
+	pq.Close()
+
+	reopened, err := NewPersistentDelayQe[string](dir, JSONCodec[string]{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	if msg, _ = reopened.Search("job"); msg == nil || msg.Content() != "new" {
+		t.Fatalf("expect re-added job recovered got %v", msg)
+	}
+}
+
+func Test_PersistentSnapshotOnAdd(t *testing.T) {
+	dir := t.TempDir()
+	pq, err := NewPersistentDelayQe[string](dir, JSONCodec[string]{}, WithSnapshotEvery(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = pq.Add(NewMessageItem("k1", "k1", time.Now().Add(time.Hour))); err != nil {
+		t.Fatal(err)
+	}
+	// 模拟进程崩溃：Add触发的快照需包含刚添加的记录
+	pq.q.Close()
+	reopened, err := NewPersistentDelayQe[string](dir, JSONCodec[string]{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	if msg, _ := reopened.Search("k1"); msg == nil {
+		t.Fatal("expect k1 recovered from snapshot")
+	}
+}
+
+func Test_PersistentSchedule(t *testing.T) {
+	dir := t.TempDir()
+	pq, err := NewPersistentDelayQe[string](dir, JSONCodec[string]{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now().Add(time.Hour)
+	until := start.Add(24 * time.Hour)
+	_ = pq.Add(NewMessageItem("every", "every", start, WithSchedule(Every(time.Minute)), WithMaxCount(3)))
+	_ = pq.Add(NewMessageItem("cron", "cron", start, WithSchedule(MustParseCron("0 9 * * *")), WithUntil(until)))
+	pq.Close()
+
+	reopened, err := NewPersistentDelayQe[string](dir, JSONCodec[string]{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	every, _ := reopened.Search("every")
+	if every == nil || every.schedule != Every(time.Minute) || every.maxCount != 3 || every.Occurrence() != 1 {
+		t.Fatalf("expect every schedule recovered got %+v", every)
+	}
+	if next := every.next(start); next == nil || !next.Time().Equal(start.Add(time.Minute)) || next.Occurrence() != 2 {
+		t.Fatalf("unexpected next occurrence %+v", next)
+	}
+	cron, _ := reopened.Search("cron")
+	if cron == nil || cron.schedule == nil || !cron.until.Equal(until) {
+		t.Fatalf("expect cron schedule recovered got %+v", cron)
+	}
+	if expect := MustParseCron("0 9 * * *").Next(start); !cron.schedule.Next(start).Equal(expect) {
+		t.Fatalf("expect cron next %s got %s", expect, cron.schedule.Next(start))
+	}
+}
+
+func Test_PersistentPriority(t *testing.T) {
+	dir := t.TempDir()
+	pq, err := NewPersistentDelayQe[string](dir, JSONCodec[string]{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec := time.Now().Add(-time.Second)
+	_ = pq.Add(NewMessageItem("low", "", sec, WithPriority(-1)))
+	_ = pq.Add(NewMessageItem("normal", "", sec))
+	_ = pq.Add(NewMessageItem("high", "", sec, WithPriority(10)))
+	pq.Close()
+
+	reopened, err := NewPersistentDelayQe[string](dir, JSONCodec[string]{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for _, expect := range []string{"high", "normal", "low"} {
+		msg, err := reopened.Watch(ctx)
+		if err != nil || msg.Key() != expect {
+			t.Fatalf("expect %s got %v %v", expect, msg, err)
+		}
+	}
+}
+
+func Test_PersistentCapacity(t *testing.T) {
+	dir := t.TempDir()
+	pq, err := NewPersistentDelayQe[string](dir, JSONCodec[string]{}, WithCapacity(2, EvictFurthest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i := 3; i >= 1; i-- {
+		if err = pq.Add(NewMessageItem(string(rune('0'+i)), "", now.Add(time.Duration(i)*time.Hour))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(pq.pending) != 2 {
+		t.Fatalf("expect evicted record removed got %d", len(pq.pending))
+	}
+	// 模拟进程崩溃，从日志中恢复
+	pq.q.Close()
+
+	// 恢复不受容量上限限制，调小容量后仍可重启
+	pq, err = NewPersistentDelayQe[string](dir, JSONCodec[string]{}, WithCapacity(1, BlockWhenFull))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pq.Close()
+	if msg, _ := pq.Search("3"); msg != nil || pq.Len() != 2 || len(pq.pending) != 2 {
+		t.Fatalf("expect 1 and 2 recovered got len %d", pq.Len())
+	}
+}
+
+func Test_PersistentCorruptRecord(t *testing.T) {
+	dir := t.TempDir()
+	pq, err := NewPersistentDelayQe[string](dir, JSONCodec[string]{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = pq.Add(NewMessageItem("k1", "v1", time.Now().Add(time.Hour)))
+	// 模拟日志中间的记录损坏：损坏记录之后仍有完整记录
+	_, _ = pq.wal.WriteString("{\"op\":1,\"key\n")
+	_ = pq.Add(NewMessageItem("k2", "v2", time.Now().Add(time.Hour)))
+	pq.q.Close()
+
+	if _, err = NewPersistentDelayQe[string](dir, JSONCodec[string]{}); err == nil {
+		t.Fatal("expect error for corrupt record in the middle of wal")
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, walFileName))
+	if !strings.Contains(string(data), `"k2"`) {
+		t.Fatal("expect wal kept after failed recovery")
+	}
+}
@@ -0,0 +1,37 @@
+package delayQueue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"git.woa.com/kf_cdms/go-public/delayQueue"
+)
+
+func Test_PriorityOrder(t *testing.T) {
+	for name, backend := range backends {
+		clock := newFakeClock()
+		q := delayQueue.New[string](delayQueue.WithBackend(backend), delayQueue.WithClock(clock))
+		sec := clock.Now().Add(time.Second)
+		_ = q.Add(delayQueue.NewMessageItem("low-1", "", sec, delayQueue.WithPriority(-1)))
+		_ = q.Add(delayQueue.NewMessageItem("normal-1", "", sec))
+		_ = q.Add(delayQueue.NewMessageItem("high-1", "", sec, delayQueue.WithPriority(10)))
+		_ = q.Add(delayQueue.NewMessageItem("normal-2", "", sec))
+		_ = q.Add(delayQueue.NewMessageItem("high-2", "", sec, delayQueue.WithPriority(10)))
+		_ = q.Add(delayQueue.NewMessageItem("earlier", "", sec.Add(-time.Millisecond), delayQueue.WithPriority(-100)))
+		_ = q.Add(delayQueue.NewMessageItem("normal-3", "", sec))
+		advance(clock, time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		for _, expect := range []string{"earlier", "high-1", "high-2", "normal-1", "normal-2", "normal-3", "low-1"} {
+			msg, err := q.Watch(ctx)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if msg.Key() != expect {
+				t.Fatalf("%s: expect %s got %s", name, expect, msg.Key())
+			}
+		}
+		cancel()
+		q.Close()
+	}
+}
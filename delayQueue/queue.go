@@ -13,44 +13,81 @@ type messageTyps any
 
 // HeapDelayQueue 延迟队列实现(最小堆)
 type HeapDelayQueue[T messageTyps] struct {
-	m     map[string]*MessageItem[T]           // 用于消息去重/查询，消息key必须是string类型，且唯一
-	heap  *structure.HeapArea[*MessageItem[T]] // 最小堆
-	lock  sync.RWMutex                         // 加把锁
-	timer *time.Timer                          // 最近一个任务的timer
+	m        map[string]*MessageItem[T]           // 用于消息去重/查询，消息key必须是string类型，且唯一
+	heap     *structure.HeapArea[*MessageItem[T]] // 最小堆
+	lock     sync.RWMutex                         // 加把锁
+	timer    Timer                                // 最近一个任务的timer
+	done     chan struct{}                        // 关闭信号
+	once     sync.Once                            // 保证只关闭一次
+	runner   *runner[T]                           // Run启动的分发器
+	seq      uint64                               // 入队序号
+	stats    queueStats                           // 统计计数
+	clock    Clock                                // 时钟
+	paused   bool                                 // 是否暂停触发
+	capacity int                                  // 容量上限，<=0表示不限
+	full     CapacityPolicy                       // 达到容量上限时的策略
+	space    *sync.Cond                           // 容量阻塞时等待空位
+	onEvict  func(msg *MessageItem[T])            // EvictFurthest移除消息后的回调，在持有锁时调用
 }
 
 // NewDelayQe 初始化延迟队列
-func NewDelayQe[T messageTyps]() *HeapDelayQueue[T] {
-	tm := time.NewTimer(time.Second)
+func NewDelayQe[T messageTyps](opts ...Option) *HeapDelayQueue[T] {
+	o := newOptions(opts)
+	tm := o.clock.NewTimer(time.Second)
 	tm.Stop() // 队列创建时无任务，所以将计时器置为stop状态
-	return &HeapDelayQueue[T]{
+	hd := &HeapDelayQueue[T]{
 		m: make(map[string]*MessageItem[T]),
-		heap: structure.NewHeapArea[*MessageItem[T]](false, func(data []*MessageItem[T], i, j int) bool {
-			if i == j {
-				return true
-			}
-			return data[i].sec.Before(data[j].sec)
+		heap: structure.NewHeapArea[*MessageItem[T]](false, heapLess[T]).WithIndex(func(msg *MessageItem[T], idx int) {
+			msg.index = idx
 		}),
-		lock:  sync.RWMutex{},
-		timer: tm,
+		lock:     sync.RWMutex{},
+		timer:    tm,
+		done:     make(chan struct{}),
+		stats:    newQueueStats(o.metrics),
+		clock:    o.clock,
+		capacity: o.capacity,
+		full:     o.full,
+	}
+	hd.space = sync.NewCond(&hd.lock)
+	return hd
+}
+
+// heapLess 堆的比较函数，按触发顺序排列
+func heapLess[T messageTyps](data []*MessageItem[T], i, j int) bool {
+	if i == j {
+		return true
 	}
+	return data[i].before(data[j])
 }
 
-// Add 添加任务
+// Add 添加任务，设置了容量上限时按 WithCapacity 的策略处理
 //
 // @param key 消息id，需保持唯一
 // @param msg 消息体结构
 func (hd *HeapDelayQueue[T]) Add(msg *MessageItem[T]) error {
 	hd.lock.Lock()
 	defer hd.lock.Unlock()
-	// 更新全局定时器 第一个任务 或者 新加入的任务执行时间比最快执行的任务时间还要早
-	if len(hd.m) < 1 || msg.sec.Before(hd.heap.Get(0).sec) {
-		hd.resetTimerWithDelay(msg.sec.Sub(time.Now()))
+	msg.resolve(hd.clock.Now())
+	if err := hd.reserve(msg, true); err != nil {
+		return err
 	}
-	if _, ok := hd.m[msg.key]; ok {
+	return hd.add(msg)
+}
+
+func (hd *HeapDelayQueue[T]) add(msg *MessageItem[T]) error {
+	if _, ok := hd.m[msg.id()]; ok {
+		hd.stats.onDuplicate(msg.id())
 		return RepeatError
 	}
-	hd.m[msg.key] = msg
+	msg.resolve(hd.clock.Now())
+	hd.stats.onAdd(msg.id())
+	hd.seq++
+	msg.seq = hd.seq
+	// 更新全局定时器 第一个任务 或者 新加入的任务执行时间比最快执行的任务时间还要早
+	if len(hd.m) < 1 || msg.sec.Before(hd.heap.Get(0).sec) {
+		hd.resetTimerWithDelay(msg.sec.Sub(hd.clock.Now()))
+	}
+	hd.m[msg.id()] = msg
 	hd.heap.Push(msg)
 	return nil
 }
@@ -70,61 +107,160 @@ func (hd *HeapDelayQueue[T]) Search(key string) (*MessageItem[T], error) {
 	return hd.m[key], nil
 }
 
+// Len 待触发的任务数量
+func (hd *HeapDelayQueue[T]) Len() int {
+	hd.lock.RLock()
+	defer hd.lock.RUnlock()
+	return len(hd.m)
+}
+
 // Delete 移除任务
 func (hd *HeapDelayQueue[T]) Delete(key string) error {
 	hd.lock.Lock()
 	defer hd.lock.Unlock()
-	return hd.delete(key)
+	if err := hd.delete(key); err != nil {
+		return err
+	}
+	hd.stats.onDelete(key)
+	return nil
 }
 
 func (hd *HeapDelayQueue[T]) delete(key string) error {
-	idx := hd.heap.Search(hd.m[key])
-	if idx < 0 {
+	msg, ok := hd.m[key]
+	if !ok {
 		return KeyError
 	}
+	idx := msg.index
 	hd.heap.Delete(idx)
 	delete(hd.m, key)
+	hd.space.Broadcast()
 	// 移除前需要判断全局定时器是否会受到影响
 	if idx == 0 {
-		if len(hd.m) == 0 { // 当任务集为空时，停止任务
-			hd.timer.Stop()
-		} else { // 当还存在任务集时，重置到下一个任务
-			hd.resetTimerWithDelay(hd.heap.Get(0).sec.Sub(time.Now()))
-		}
+		hd.resetTimer()
+	}
+	return nil
+}
+
+// Reschedule 修改任务的过期时间，复杂度O(log2n)
+func (hd *HeapDelayQueue[T]) Reschedule(key string, sec time.Time) error {
+	hd.lock.Lock()
+	defer hd.lock.Unlock()
+	msg, ok := hd.m[key]
+	if !ok {
+		return KeyError
 	}
+	msg.sec = sec
+	hd.heap.Fix(msg.index)
+	hd.resetTimer()
 	return nil
 }
 
+// UpdateContent 修改任务的消息内容，不影响触发时间
+func (hd *HeapDelayQueue[T]) UpdateContent(key string, content T) error {
+	hd.lock.Lock()
+	defer hd.lock.Unlock()
+	msg, ok := hd.m[key]
+	if !ok {
+		return KeyError
+	}
+	msg.content = content
+	return nil
+}
+
+// Upsert 添加任务，key已存在时原子地替换为新任务
+//
+// 替换不占用新的容量；添加新key时按 WithCapacity 的策略处理但不会阻塞，BlockWhenFull按RejectWhenFull处理
+func (hd *HeapDelayQueue[T]) Upsert(msg *MessageItem[T]) error {
+	hd.lock.Lock()
+	defer hd.lock.Unlock()
+	if old, ok := hd.m[msg.id()]; ok {
+		hd.heap.Delete(old.index)
+		delete(hd.m, msg.id())
+	} else {
+		msg.resolve(hd.clock.Now())
+		if err := hd.reserve(msg, false); err != nil {
+			return err
+		}
+	}
+	err := hd.add(msg)
+	hd.resetTimer()
+	return err
+}
+
 // Watch 监听延迟队列，该方法会阻塞，直到延迟队列最早的事件触发
 //
 // @param ctx 通过ctx.Done控制读取延迟的阻塞停止
 func (hd *HeapDelayQueue[T]) Watch(ctx context.Context) (*MessageItem[T], error) {
-	select {
-	case <-ctx.Done():
-		return nil, CtxDoneError
-	case <-hd.timer.C:
-		hd.lock.Lock() // 防止同时触发Delete,出现幻读
-		defer hd.lock.Unlock()
-		if len(hd.m) == 0 {
-			return nil, EmptyQueue
-		}
-		messageItem := hd.heap.Get(0)
-		if err := hd.delete(messageItem.key); err != nil {
-			return nil, err
-		}
-		if len(hd.m) > 0 {
-			hd.resetTimerWithDelay(hd.heap.Get(0).sec.Sub(time.Now()))
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, CtxDoneError
+		case <-hd.done:
+			return nil, ClosedError
+		case <-hd.timer.C():
+			if msg, ok, err := hd.fire(); ok {
+				return msg, err
+			}
 		}
-		return messageItem, nil
 	}
 }
 
+// fire 取出到期的堆顶任务。堆顶任务尚未到期时(如刚被Reschedule推迟)重置定时器并返回ok=false，继续等待
+func (hd *HeapDelayQueue[T]) fire() (*MessageItem[T], bool, error) {
+	hd.lock.Lock() // 防止同时触发Delete,出现幻读
+	defer hd.lock.Unlock()
+	if hd.paused { // 暂停前已触发的定时器
+		return nil, false, nil
+	}
+	if len(hd.m) == 0 {
+		return nil, true, EmptyQueue
+	}
+	messageItem := hd.heap.Get(0)
+	now := hd.clock.Now()
+	if messageItem.sec.After(now) {
+		hd.resetTimer()
+		return nil, false, nil
+	}
+	if err := hd.delete(messageItem.id()); err != nil {
+		return nil, true, err
+	}
+	hd.stats.onFire(messageItem.id(), now.Sub(messageItem.sec))
+	if next := messageItem.next(now); next != nil { // 周期消息加入下一次触发
+		_ = hd.add(next)
+	}
+	hd.resetTimer()
+	return messageItem, true, nil
+}
+
+// Close 关闭队列，阻塞中的Watch将返回ClosedError
+func (hd *HeapDelayQueue[T]) Close() {
+	hd.once.Do(func() {
+		close(hd.done)
+		hd.lock.Lock()
+		hd.space.Broadcast() // 唤醒等待空位的Add
+		hd.lock.Unlock()
+	})
+}
+
+// resetTimer 将全局定时器对齐到堆顶任务，任务集为空时停止
+func (hd *HeapDelayQueue[T]) resetTimer() {
+	if len(hd.m) == 0 {
+		hd.timer.Stop()
+		return
+	}
+	hd.resetTimerWithDelay(hd.heap.Get(0).sec.Sub(hd.clock.Now()))
+}
+
 // 清空通道的值
 func (hd *HeapDelayQueue[T]) resetTimerWithDelay(duration time.Duration) {
+	if hd.paused {
+		hd.timer.Stop()
+		return
+	}
 	// 对于已经关闭的timer,检查是否有尚未消费的timer，有的话直接移除
 	if !hd.timer.Stop() {
 		select {
-		case <-hd.timer.C:
+		case <-hd.timer.C():
 		default:
 		}
 	}
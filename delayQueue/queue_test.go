@@ -1,62 +1,175 @@
-package delayQueue
+package delayQueue_test
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
-)
 
-var delay = NewDelayQe[map[string]interface{}]()
+	"git.woa.com/kf_cdms/go-public/delayQueue"
+	"git.woa.com/kf_cdms/go-public/delayQueue/clocktest"
+)
 
-func consumer() {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+// watchN 读取n条到期消息，返回各消息的key
+func watchN(t *testing.T, delay delayQueue.DelayQueue[map[string]interface{}], n int) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	for {
+	keys := make([]string, 0, n)
+	for i := 0; i < n; i++ {
 		msg, err := delay.Watch(ctx)
 		if err != nil {
-			fmt.Println(fmt.Errorf("watch item error:%w", err).Error())
-			return
+			t.Fatalf("watch item error:%v", err)
 		}
-		v := msg.Content()
-		fmt.Printf("%#v, %s\n", v, time.Now().Format(time.DateTime))
+		keys = append(keys, msg.Key())
 	}
+	return keys
 }
 
-func product(s string) {
-	err1 := delay.Add(NewMessageItem(s+"k1", map[string]interface{}{
-		"nowTime": s + "k1",
-	}, time.Now().Add(10*time.Second)))
+func product(t *testing.T, clock delayQueue.Clock, delay delayQueue.DelayQueue[map[string]interface{}], s string) {
+	now := clock.Now()
+	for k, d := range map[string]time.Duration{"k1": 10 * time.Second, "k2": 5 * time.Second, "k3": 15 * time.Second, "k4": 15 * time.Second} {
+		if err := delay.Add(delayQueue.NewMessageItem(s+k, map[string]interface{}{
+			"nowTime": s + k,
+		}, now.Add(d))); err != nil {
+			t.Errorf("add %s error:%v", s+k, err)
+		}
+	}
+}
 
-	err2 := delay.Add(NewMessageItem(s+"k2", map[string]interface{}{
-		"nowTime": s + "k2",
-	}, time.Now().Add(5*time.Second)))
+func Test_Queue(t *testing.T) {
+	clock := clocktest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local))
+	delay := delayQueue.NewDelayQe[map[string]interface{}](delayQueue.WithClock(clock))
+	defer delay.Close()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(s string) {
+			defer wg.Done()
+			product(t, clock, delay, s)
+		}(fmt.Sprint(i))
+	}
+	wg.Wait()
+	if delay.Len() != 40 {
+		t.Fatalf("expect 40 items got %d", delay.Len())
+	}
 
-	err3 := delay.Add(NewMessageItem(s+"k3", map[string]interface{}{
-		"nowTime": s + "k3",
-	}, time.Now().Add(15*time.Second)))
+	// 每推进5秒，恰好触发一批消息，且不会提前触发后面的消息
+	for _, step := range []struct {
+		suffix []string
+		n      int
+	}{
+		{[]string{"k2"}, 10},
+		{[]string{"k1"}, 10},
+		{[]string{"k3", "k4"}, 20},
+	} {
+		clock.Advance(5 * time.Second)
+		for _, key := range watchN(t, delay, step.n) {
+			if key[1:] != step.suffix[0] && key[1:] != step.suffix[len(step.suffix)-1] {
+				t.Fatalf("expect %v fired got %s", step.suffix, key)
+			}
+		}
+	}
+	if delay.Len() != 0 {
+		t.Fatalf("expect empty queue got %d", delay.Len())
+	}
+}
 
-	err4 := delay.Add(NewMessageItem(s+"k4", map[string]interface{}{
-		"nowTime": s + "k4",
-	}, time.Now().Add(15*time.Second)))
+func Test_QueueReschedule(t *testing.T) {
+	clock := clocktest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local))
+	delay := delayQueue.NewDelayQe[map[string]interface{}](delayQueue.WithClock(clock))
+	defer delay.Close()
+	now := clock.Now()
+	_ = delay.Add(delayQueue.NewMessageItem("a", map[string]interface{}{}, now.Add(time.Minute)))
+	_ = delay.Add(delayQueue.NewMessageItem("b", map[string]interface{}{}, now.Add(2*time.Minute)))
+	if err := delay.Reschedule("b", now.Add(30*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(30 * time.Second)
+	if keys := watchN(t, delay, 1); keys[0] != "b" {
+		t.Fatalf("expect rescheduled b first got %v", keys)
+	}
+	// 推迟后的任务不应在原时间触发
+	if err := delay.Reschedule("a", now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Minute)
+	if delay.Len() != 1 || clock.Timers() != 1 {
+		t.Fatalf("expect a pending got len %d timers %d", delay.Len(), clock.Timers())
+	}
+	clock.Set(now.Add(time.Hour))
+	if keys := watchN(t, delay, 1); keys[0] != "a" {
+		t.Fatalf("expect a got %v", keys)
+	}
+	if stats := delay.Stats(); stats.Fired != 2 || stats.Lag.Max != 0 {
+		t.Fatalf("expect 2 fired without lag got %+v", stats)
+	}
+}
+
+func Test_QueueFakeClockReplay(t *testing.T) {
+	clock := clocktest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local))
+	hd := delayQueue.NewDelayQe[string](delayQueue.WithClock(clock))
+	defer hd.Close()
+	dlq := delayQueue.NewDeadLetterQueue[string](delayQueue.WithClock(clock))
+	_ = hd.Add(delayQueue.NewMessageItem("bad", "bad", clock.Now()))
+	done := make(chan error)
+	go func() {
+		done <- hd.Run(context.Background(), 1, func(ctx context.Context, msg *delayQueue.MessageItem[string]) error {
+			return fmt.Errorf("fail %s", msg.Key())
+		}, delayQueue.WithRetry(delayQueue.FixedRetry(time.Minute, 1), dlq))
+	}()
+	for dlq.Len() < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	hd.Stop()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if dl := dlq.Get("bad"); !dl.Time.Equal(clock.Now()) {
+		t.Fatalf("expect dead letter time %s got %s", clock.Now(), dl.Time)
+	}
+	// 重放的死信按队列时钟立即触发
+	if err := dlq.Replay(hd); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if msg, err := hd.Watch(ctx); err != nil || msg.Key() != "bad" {
+		t.Fatalf("expect replayed bad got %v %v", msg, err)
+	}
 
-	fmt.Printf("%#v\n", errors.Join(err1, err2, err3, err4))
+	// 未指定首次触发时间的周期消息按队列时钟计算
+	_ = hd.Add(delayQueue.NewMessageItem("tick", "tick", time.Time{}, delayQueue.WithSchedule(delayQueue.Every(time.Minute))))
+	if msg, _ := hd.Search("tick"); !msg.Time().Equal(clock.Now().Add(time.Minute)) {
+		t.Fatalf("expect first tick after 1 minute got %s", msg.Time())
+	}
+	clock.Advance(time.Minute)
+	if msg, err := hd.Watch(ctx); err != nil || msg.Key() != "tick" {
+		t.Fatalf("expect tick got %v %v", msg, err)
+	}
 }
 
-func Test_Queue(t *testing.T) {
-	fmt.Println(time.Now().Format(time.DateTime))
-	go consumer()
-	go product("1")
-	go product("2")
-	go product("3")
-	go product("4")
-	go product("5")
-	go product("6")
-	go product("7")
-	go product("8")
-	go product("9")
-	go product("0")
-	time.Sleep(time.Second * 60)
-	fmt.Println(time.Now().Format(time.DateTime))
+func Test_PauseResume(t *testing.T) {
+	clock := clocktest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local))
+	hd := delayQueue.NewDelayQe[int](delayQueue.WithClock(clock))
+	defer hd.Close()
+	now := clock.Now()
+	_ = hd.Add(delayQueue.NewMessageItem("k2", 2, now.Add(2*time.Second)))
+	hd.Pause()
+	_ = hd.Add(delayQueue.NewMessageItem("k1", 1, now.Add(time.Second)))
+	_ = hd.Add(delayQueue.NewMessageItem("k3", 3, now.Add(3*time.Second)))
+	// 暂停期间定时器停止，全部到期也不会触发
+	clock.Advance(5 * time.Second)
+	if !hd.Paused() || hd.Len() != 3 || clock.Timers() != 0 {
+		t.Fatalf("expect nothing fired while paused, len %d timers %d", hd.Len(), clock.Timers())
+	}
+	hd.Resume()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for i := 1; i <= 3; i++ {
+		msg, err := hd.Watch(ctx)
+		if err != nil || msg.Content() != i {
+			t.Fatalf("expect %d got %v %v", i, msg, err)
+		}
+	}
 }
@@ -0,0 +1,66 @@
+package delayQueue_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"git.woa.com/kf_cdms/go-public/delayQueue"
+)
+
+// 两种实现共有的修改接口
+type mutableQueue[T any] interface {
+	delayQueue.DelayQueue[T]
+	Reschedule(key string, sec time.Time) error
+	UpdateContent(key string, content T) error
+	Upsert(msg *delayQueue.MessageItem[T]) error
+}
+
+func Test_Reschedule(t *testing.T) {
+	for name, backend := range backends {
+		clock := newFakeClock()
+		q := delayQueue.New[string](delayQueue.WithBackend(backend), delayQueue.WithClock(clock)).(mutableQueue[string])
+		now := clock.Now()
+		_ = q.Add(delayQueue.NewMessageItem("k1", "v1", now.Add(time.Hour)))
+		_ = q.Add(delayQueue.NewMessageItem("k2", "v2", now.Add(time.Second)))
+		_ = q.Add(delayQueue.NewMessageItem("k3", "v3", now.Add(2*time.Hour)))
+		if err := q.Reschedule("k1", now); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err := q.Reschedule("k2", now.Add(3*time.Hour)); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err := q.Reschedule("nope", now); !errors.Is(err, delayQueue.KeyError) {
+			t.Fatalf("%s: expect KeyError got %v", name, err)
+		}
+		if err := q.UpdateContent("k1", "v1-updated"); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err := q.Upsert(delayQueue.NewMessageItem("k3", "v3-upserted", now.Add(-time.Second))); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err := q.Upsert(delayQueue.NewMessageItem("k4", "v4", now.Add(4*time.Hour))); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if q.Len() != 4 {
+			t.Fatalf("%s: expect len 4 got %d", name, q.Len())
+		}
+		if msg := q.Peek(); msg == nil || msg.Key() != "k3" {
+			t.Fatalf("%s: expect peek k3 got %v", name, msg)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		for _, expect := range []string{"v3-upserted", "v1-updated"} {
+			msg, err := q.Watch(ctx)
+			if err != nil || msg.Content() != expect {
+				t.Fatalf("%s: expect %s got %v %v", name, expect, msg, err)
+			}
+		}
+		// k2 已被推迟，原定时间到达后不应触发。第二次推进会等待第一次推进处理完成
+		advance(clock, time.Second)
+		advance(clock, time.Second)
+		expectNone[string](t, q)
+		cancel()
+		q.Close()
+	}
+}
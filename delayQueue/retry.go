@@ -0,0 +1,176 @@
+package delayQueue
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RetryPolicy 重试策略
+type RetryPolicy interface {
+	// Next 消息第attempts次处理失败后的重试延迟，返回false表示不再重试
+	Next(attempts int) (time.Duration, bool)
+}
+
+// fixedRetry 固定间隔重试
+type fixedRetry struct {
+	delay       time.Duration
+	maxAttempts int
+}
+
+// FixedRetry 固定间隔重试，最多处理maxAttempts次(含首次)，maxAttempts<=0表示不限次数
+func FixedRetry(delay time.Duration, maxAttempts int) RetryPolicy {
+	return fixedRetry{delay: delay, maxAttempts: maxAttempts}
+}
+
+func (f fixedRetry) Next(attempts int) (time.Duration, bool) {
+	if f.maxAttempts > 0 && attempts >= f.maxAttempts {
+		return 0, false
+	}
+	return f.delay, true
+}
+
+// exponentialRetry 指数退避重试
+type exponentialRetry struct {
+	backoff     func(attempts int) time.Duration
+	jitter      float64
+	maxAttempts int
+}
+
+// ExponentialRetry 指数退避重试：base * 2^(attempts-1)，不超过max
+//
+// @param jitter 抖动比例[0,1]，实际延迟在 延迟*(1±jitter) 之间随机，避免大量消息同时重试
+// @param maxAttempts 最多处理次数(含首次)，<=0表示不限次数
+func ExponentialRetry(base, max time.Duration, jitter float64, maxAttempts int) RetryPolicy {
+	if jitter < 0 {
+		jitter = 0
+	}
+	if jitter > 1 {
+		jitter = 1
+	}
+	return exponentialRetry{backoff: ExponentialBackoff(base, max), jitter: jitter, maxAttempts: maxAttempts}
+}
+
+func (e exponentialRetry) Next(attempts int) (time.Duration, bool) {
+	if e.maxAttempts > 0 && attempts >= e.maxAttempts {
+		return 0, false
+	}
+	d := e.backoff(attempts)
+	if e.jitter > 0 {
+		d = time.Duration(float64(d) * (1 + e.jitter*(2*rand.Float64()-1)))
+	}
+	return d, true
+}
+
+// DeadLetter 死信，重试耗尽的消息
+type DeadLetter[T messageTyps] struct {
+	Item *MessageItem[T] // 消息，Attempts为已处理的次数
+	Err  error           // 最后一次处理的错误
+	Time time.Time       // 进入死信队列的时间
+}
+
+// DeadLetterQueue 死信队列，可查看、重放或清除重试耗尽的消息。并发安全
+type DeadLetterQueue[T messageTyps] struct {
+	m     map[string]*DeadLetter[T]
+	clock Clock // 时钟，用于记录进入时间及重放时的触发时间
+	lock  sync.RWMutex
+}
+
+// NewDeadLetterQueue 初始化死信队列，支持 WithClock，队列使用 clocktest.FakeClock 时需传入同一时钟
+func NewDeadLetterQueue[T messageTyps](opts ...Option) *DeadLetterQueue[T] {
+	return &DeadLetterQueue[T]{
+		m:     make(map[string]*DeadLetter[T]),
+		clock: newOptions(opts).clock,
+	}
+}
+
+// put 加入死信，同一个key重复加入时覆盖
+func (d *DeadLetterQueue[T]) put(msg *MessageItem[T], err error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.m[msg.id()] = &DeadLetter[T]{Item: msg, Err: err, Time: d.clock.Now()}
+}
+
+// Len 死信数量
+func (d *DeadLetterQueue[T]) Len() int {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return len(d.m)
+}
+
+// Get 查询死信，不存在时返回nil
+func (d *DeadLetterQueue[T]) Get(key string) *DeadLetter[T] {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return d.m[key]
+}
+
+// List 按进入时间返回全部死信
+func (d *DeadLetterQueue[T]) List() []*DeadLetter[T] {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	res := make([]*DeadLetter[T], 0, len(d.m))
+	for _, dl := range d.m {
+		res = append(res, dl)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Time.Before(res[j].Time)
+	})
+	return res
+}
+
+// Replay 将死信重新加入延迟队列并立即触发，投递次数清零。不传key时重放全部死信
+//
+// 加入成功的死信会被移除，失败的(如key已存在于队列中)保留，并返回合并后的错误
+func (d *DeadLetterQueue[T]) Replay(q DelayQueue[T], keys ...string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	var errs []error
+	for _, key := range d.keys(keys) {
+		dl, ok := d.m[key]
+		if !ok {
+			errs = append(errs, KeyError)
+			continue
+		}
+		msg := dl.Item.clone()
+		msg.sec = d.clock.Now()
+		msg.attempts = 0
+		if err := q.Add(msg); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delete(d.m, key)
+	}
+	return errors.Join(errs...)
+}
+
+// Purge 清除死信，不传key时清空全部，返回清除的数量
+func (d *DeadLetterQueue[T]) Purge(keys ...string) int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	n := 0
+	for _, key := range d.keys(keys) {
+		if _, ok := d.m[key]; ok {
+			delete(d.m, key)
+			n++
+		}
+	}
+	return n
+}
+
+// keys 未指定key时按进入时间返回全部key
+func (d *DeadLetterQueue[T]) keys(keys []string) []string {
+	if len(keys) > 0 {
+		return keys
+	}
+	all := make([]string, 0, len(d.m))
+	for key := range d.m {
+		all = append(all, key)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return d.m[all[i]].Time.Before(d.m[all[j]].Time)
+	})
+	return all
+}
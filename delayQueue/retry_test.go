@@ -0,0 +1,117 @@
+package delayQueue
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_RunRetryDeadLetter(t *testing.T) {
+	hd := NewDelayQe[string]()
+	defer hd.Close()
+	dlq := NewDeadLetterQueue[string]()
+	_ = hd.Add(NewMessageItem("bad", "bad", time.Now()))
+	_ = hd.Add(NewMessageItem("flaky", "flaky", time.Now()))
+	var (
+		calls     atomic.Int32
+		succeeded atomic.Int32
+		hooked    atomic.Int32
+	)
+	handler := func(ctx context.Context, msg *MessageItem[string]) error {
+		calls.Add(1)
+		if msg.Key() == "bad" {
+			panic("always fail")
+		}
+		if msg.Key() == "flaky" && msg.Attempts() < 1 {
+			return errors.New("first attempt fails")
+		}
+		succeeded.Add(1)
+		return nil
+	}
+	go func() {
+		for dlq.Len() < 1 || succeeded.Load() < 1 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		hd.Stop()
+	}()
+	err := hd.Run(context.Background(), 2, handler,
+		WithRetry(FixedRetry(50*time.Millisecond, 3), dlq),
+		WithErrorHook(func(msg *MessageItem[string], err error) { hooked.Add(1) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// bad 处理3次，flaky 处理2次
+	if calls.Load() != 5 || hooked.Load() != 4 {
+		t.Fatalf("expect 5 calls and 4 errors got %d %d", calls.Load(), hooked.Load())
+	}
+	dl := dlq.Get("bad")
+	if dl == nil || dl.Item.Attempts() != 3 || !errors.Is(dl.Err, PanicError) {
+		t.Fatalf("unexpected dead letter %+v", dl)
+	}
+	if list := dlq.List(); len(list) != 1 || list[0] != dl {
+		t.Fatalf("unexpected dead letter list %v", list)
+	}
+
+	// 重放后消息回到队列并立即触发
+	if err = dlq.Replay(hd); err != nil {
+		t.Fatal(err)
+	}
+	if dlq.Len() != 0 || hd.Len() != 1 {
+		t.Fatalf("expect replayed, dlq %d queue %d", dlq.Len(), hd.Len())
+	}
+	msg, err := hd.Watch(context.Background())
+	if err != nil || msg.Key() != "bad" || msg.Attempts() != 0 {
+		t.Fatalf("expect replayed bad got %v %v", msg, err)
+	}
+	if err = dlq.Replay(hd, "missing"); !errors.Is(err, KeyError) {
+		t.Fatalf("expect KeyError got %v", err)
+	}
+	dlq.put(msg, errors.New("again"))
+	if n := dlq.Purge(); n != 1 || dlq.Len() != 0 {
+		t.Fatalf("expect purge 1 got %d", n)
+	}
+}
+
+func Test_RetryPolicy(t *testing.T) {
+	fixed := FixedRetry(time.Second, 2)
+	if d, ok := fixed.Next(1); !ok || d != time.Second {
+		t.Fatalf("unexpected fixed retry %v %v", d, ok)
+	}
+	if _, ok := fixed.Next(2); ok {
+		t.Fatal("expect fixed retry exhausted")
+	}
+	exp := ExponentialRetry(time.Second, time.Minute, 0.2, 0)
+	for attempts := 1; attempts < 20; attempts++ {
+		d, ok := exp.Next(attempts)
+		base := ExponentialBackoff(time.Second, time.Minute)(attempts)
+		if !ok || d < base*8/10 || d > base*12/10 {
+			t.Fatalf("attempts %d: %v out of jitter range of %v", attempts, d, base)
+		}
+	}
+}
+
+func Test_RunRetryWhenFull(t *testing.T) {
+	hd := NewDelayQe[string](WithCapacity(1, BlockWhenFull))
+	defer hd.Close()
+	dlq := NewDeadLetterQueue[string]()
+	_ = hd.Add(NewMessageItem("k", "k", time.Now()))
+	handler := func(ctx context.Context, msg *MessageItem[string]) error {
+		// 处理期间占满队列，重试无法入队
+		_ = hd.Add(NewMessageItem("other", "other", time.Now().Add(time.Hour)))
+		return errors.New("fail")
+	}
+	go func() {
+		for dlq.Len() < 1 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		hd.Stop()
+	}()
+	if err := hd.Run(context.Background(), 1, handler, WithRetry(FixedRetry(time.Millisecond, 3), dlq)); err != nil {
+		t.Fatal(err)
+	}
+	if dl := dlq.Get("k"); dl == nil || !errors.Is(dl.Err, FullError) || hd.Len() != 1 {
+		t.Fatalf("unexpected dead letter %+v", dl)
+	}
+}
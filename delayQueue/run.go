@@ -0,0 +1,168 @@
+package delayQueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Handler 消息处理函数
+type Handler[T messageTyps] func(ctx context.Context, msg *MessageItem[T]) error
+
+// RunOption Run的配置项
+type RunOption[T messageTyps] func(r *runner[T])
+
+// WithErrorHook 处理函数返回错误或panic(包装为PanicError)时的回调
+func WithErrorHook[T messageTyps](hook func(msg *MessageItem[T], err error)) RunOption[T] {
+	return func(r *runner[T]) {
+		r.onError = hook
+	}
+}
+
+// WithRetry 处理失败(含panic)时按重试策略重新入队，重试耗尽或无法重新入队时放入死信队列
+//
+// @param dlq 死信队列，传nil时重试耗尽的消息直接丢弃
+func WithRetry[T messageTyps](policy RetryPolicy, dlq *DeadLetterQueue[T]) RunOption[T] {
+	return func(r *runner[T]) {
+		r.retry = policy
+		r.dlq = dlq
+	}
+}
+
+// runner 单个分发协程调用Watch，再将到期消息分发给多个worker处理，避免多个协程争抢同一个timer
+type runner[T messageTyps] struct {
+	ctx     context.Context                      // 分发使用的ctx，停止时取消
+	cancel  context.CancelFunc                   // 停止分发
+	hctx    context.Context                      // 处理函数使用的ctx，不随停止信号取消，保证已出队的消息能处理完成
+	done    chan struct{}                        // 所有worker退出后关闭
+	onError func(msg *MessageItem[T], err error) // 错误回调
+	retry   RetryPolicy                          // 重试策略
+	dlq     *DeadLetterQueue[T]                  // 死信队列
+	requeue func(msg *MessageItem[T]) error      // 重试时重新入队
+	clock   Clock                                // 时钟
+}
+
+func newRunner[T messageTyps](ctx context.Context, opts []RunOption[T]) *runner[T] {
+	r := &runner[T]{done: make(chan struct{}), hctx: context.WithoutCancel(ctx)}
+	r.ctx, r.cancel = context.WithCancel(ctx)
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// run 阻塞分发消息，直到ctx结束、被stop或Watch返回异常，返回前等待所有处理中的消息完成
+func (r *runner[T]) run(workers int, watch func(ctx context.Context) (*MessageItem[T], error), handler Handler[T]) error {
+	if workers < 1 {
+		workers = 1
+	}
+	defer r.cancel()
+	var (
+		jobs = make(chan *MessageItem[T])
+		wg   sync.WaitGroup
+		err  error
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for msg := range jobs {
+				r.handle(handler, msg)
+			}
+		}()
+	}
+	for {
+		msg, werr := watch(r.ctx)
+		if werr != nil {
+			if errors.Is(werr, EmptyQueue) { // 定时器触发时任务已被删除，继续等待
+				continue
+			}
+			if !errors.Is(werr, CtxDoneError) {
+				err = werr
+			}
+			break
+		}
+		jobs <- msg
+	}
+	close(jobs)
+	wg.Wait()
+	close(r.done)
+	return err
+}
+
+// handle 执行处理函数，捕获panic并回调错误
+func (r *runner[T]) handle(handler Handler[T], msg *MessageItem[T]) {
+	var err error
+	func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				err = fmt.Errorf("%w: %v", PanicError, rec)
+			}
+		}()
+		err = handler(r.hctx, msg)
+	}()
+	if err == nil {
+		return
+	}
+	msg.attempts++
+	if r.onError != nil {
+		r.onError(msg, err)
+	}
+	if r.retry == nil {
+		return
+	}
+	if delay, ok := r.retry.Next(msg.attempts); ok {
+		retry := msg.clone()
+		retry.sec = r.clock.Now().Add(delay)
+		// 周期消息的下一次触发已占用同一个key或队列已满时无法重新入队，同样放入死信队列
+		rerr := r.requeue(retry)
+		if rerr == nil {
+			return
+		}
+		err = errors.Join(err, rerr)
+	}
+	if r.dlq != nil {
+		r.dlq.put(msg, err)
+	}
+}
+
+// stop 停止分发并等待处理中的消息完成
+func (r *runner[T]) stop() {
+	r.cancel()
+	<-r.done
+}
+
+// Run 启动workers个协程并发处理到期消息，阻塞直到ctx结束、调用Stop或队列关闭
+//
+// 只有一个分发协程调用Watch，到期消息逐个交给空闲的worker；停止时不再取出新消息，
+// 并等待已取出的消息处理完成后返回。同一个队列同时只能有一个Run，重复调用返回RunningError。
+func (hd *HeapDelayQueue[T]) Run(ctx context.Context, workers int, handler Handler[T], opts ...RunOption[T]) error {
+	hd.lock.Lock()
+	if hd.runner != nil {
+		hd.lock.Unlock()
+		return RunningError
+	}
+	r := newRunner(ctx, opts)
+	r.requeue = hd.tryAdd // 队列已满时不阻塞worker，直接放入死信队列
+	r.clock = hd.clock
+	hd.runner = r
+	hd.lock.Unlock()
+
+	err := r.run(workers, hd.Watch, handler)
+
+	hd.lock.Lock()
+	hd.runner = nil
+	hd.lock.Unlock()
+	return err
+}
+
+// Stop 停止Run并等待处理中的消息完成，未运行时直接返回
+func (hd *HeapDelayQueue[T]) Stop() {
+	hd.lock.RLock()
+	r := hd.runner
+	hd.lock.RUnlock()
+	if r != nil {
+		r.stop()
+	}
+}
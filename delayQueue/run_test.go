@@ -0,0 +1,101 @@
+package delayQueue
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_RunWorkers(t *testing.T) {
+	hd := NewDelayQe[int]()
+	defer hd.Close()
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		_ = hd.Add(NewMessageItem(strconv.Itoa(i), i, now.Add(time.Duration(i%10)*10*time.Millisecond)))
+	}
+	var (
+		handled  atomic.Int32
+		failed   atomic.Int32
+		panicked atomic.Int32
+		seen     sync.Map
+	)
+	go func() {
+		for handled.Load() < 100 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		hd.Stop()
+	}()
+	err := hd.Run(context.Background(), 8, func(ctx context.Context, msg *MessageItem[int]) error {
+		defer handled.Add(1)
+		if _, ok := seen.LoadOrStore(msg.Key(), true); ok {
+			t.Errorf("%s handled twice", msg.Key())
+		}
+		switch msg.Content() % 10 {
+		case 1:
+			return errors.New("handler failed")
+		case 2:
+			panic("handler panic")
+		}
+		return nil
+	}, WithErrorHook(func(msg *MessageItem[int], err error) {
+		if errors.Is(err, PanicError) {
+			panicked.Add(1)
+		} else {
+			failed.Add(1)
+		}
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handled.Load() != 100 || failed.Load() != 10 || panicked.Load() != 10 {
+		t.Fatalf("handled %d failed %d panicked %d", handled.Load(), failed.Load(), panicked.Load())
+	}
+	if hd.Len() != 0 {
+		t.Fatalf("expect empty queue got %d", hd.Len())
+	}
+}
+
+func Test_RunGracefulDrain(t *testing.T) {
+	hd := NewDelayQe[string]()
+	defer hd.Close()
+	_ = hd.Add(NewMessageItem("slow", "slow", time.Now()))
+	_ = hd.Add(NewMessageItem("later", "later", time.Now().Add(time.Hour)))
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	var finished atomic.Bool
+	go func() {
+		<-started
+		cancel()
+	}()
+	err := hd.Run(ctx, 2, func(ctx context.Context, msg *MessageItem[string]) error {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		if ctx.Err() != nil {
+			t.Error("handler ctx should not be canceled while draining")
+		}
+		finished.Store(true)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !finished.Load() {
+		t.Fatal("Run returned before in-flight handler finished")
+	}
+	if hd.Len() != 1 {
+		t.Fatalf("expect pending item kept, len %d", hd.Len())
+	}
+	// 重复Run
+	go func() {
+		_ = hd.Run(context.Background(), 1, func(context.Context, *MessageItem[string]) error { return nil })
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if err = hd.Run(context.Background(), 1, nil); !errors.Is(err, RunningError) {
+		t.Fatalf("expect RunningError got %v", err)
+	}
+	hd.Stop()
+}
@@ -0,0 +1,222 @@
+package delayQueue
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 周期消息的触发规则
+type Schedule interface {
+	// Next 返回晚于t的下一次触发时间，返回零值表示不再触发
+	Next(t time.Time) time.Time
+}
+
+// everySchedule 固定间隔
+type everySchedule struct {
+	interval time.Duration
+}
+
+// Every 按固定间隔重复触发，间隔不足1秒时按1秒处理
+func Every(interval time.Duration) Schedule {
+	if interval < time.Second {
+		interval = time.Second
+	}
+	return everySchedule{interval: interval}
+}
+
+func (e everySchedule) Next(t time.Time) time.Time {
+	return t.Add(e.interval)
+}
+
+// cronSchedule cron表达式，每个字段用位图表示允许的取值
+type cronSchedule struct {
+	second, minute, hour, dom, month, dow uint64
+	domStar, dowStar                      bool   // 日/周字段是否为*，用于决定两者是"且"还是"或"的关系
+	expr                                  string // 原始表达式，用于持久化
+}
+
+// cronField cron字段的取值范围
+type cronField struct {
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	secondField = cronField{min: 0, max: 59}
+	minuteField = cronField{min: 0, max: 59}
+	hourField   = cronField{min: 0, max: 23}
+	domField    = cronField{min: 1, max: 31}
+	monthField  = cronField{min: 1, max: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = cronField{min: 0, max: 7, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// ParseCron 解析标准cron表达式
+//
+// 支持5段(分 时 日 月 周)与6段(秒 分 时 日 月 周)两种格式，字段支持 * ? , - / 以及月份、星期的英文缩写，
+// 星期中0和7均表示周日。例如 "*/5 * * * *" 每5分钟，"30 9 * * 1-5" 工作日9:30。
+// 与标准cron一致，日和周同时指定时满足其一即触发。
+func ParseCron(expr string) (Schedule, error) {
+	fields := strings.Fields(expr)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("cron expression %q: expect 5 or 6 fields, got %d", expr, len(fields))
+	}
+	var (
+		cs  = &cronSchedule{expr: expr}
+		err error
+	)
+	specs := []struct {
+		bits  *uint64
+		field cronField
+	}{
+		{&cs.second, secondField},
+		{&cs.minute, minuteField},
+		{&cs.hour, hourField},
+		{&cs.dom, domField},
+		{&cs.month, monthField},
+		{&cs.dow, dowField},
+	}
+	for i, spec := range specs {
+		if *spec.bits, err = parseCronField(fields[i], spec.field); err != nil {
+			return nil, fmt.Errorf("cron expression %q: %w", expr, err)
+		}
+	}
+	if cs.dow&(1<<7) > 0 { // 7同样表示周日
+		cs.dow |= 1
+	}
+	cs.domStar = strings.HasPrefix(fields[3], "*") || fields[3] == "?"
+	cs.dowStar = strings.HasPrefix(fields[5], "*") || fields[5] == "?"
+	return cs, nil
+}
+
+// parseCronField 解析单个字段，返回取值位图
+func parseCronField(s string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		rangeAndStep := strings.SplitN(part, "/", 2)
+		lo, hi := f.min, f.max
+		switch expr := rangeAndStep[0]; {
+		case expr == "*" || expr == "?":
+		case strings.Contains(expr, "-"):
+			bounds := strings.SplitN(expr, "-", 2)
+			var err error
+			if lo, err = parseCronValue(bounds[0], f); err != nil {
+				return 0, err
+			}
+			if hi, err = parseCronValue(bounds[1], f); err != nil {
+				return 0, err
+			}
+		default:
+			v, err := parseCronValue(expr, f)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			if len(rangeAndStep) == 1 { // 单值；带步长时表示从该值开始到最大值
+				hi = v
+			}
+		}
+		step := uint64(1)
+		if len(rangeAndStep) == 2 {
+			var err error
+			if step, err = strconv.ParseUint(rangeAndStep[1], 10, 8); err != nil || step == 0 {
+				return 0, fmt.Errorf("invalid step %q", part)
+			}
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("invalid range %q", part)
+		}
+		for v := uint64(lo); v <= uint64(hi); v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// parseCronValue 解析字段中的单个取值(数字或英文缩写)
+func parseCronValue(s string, f cronField) (uint, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if uint(v) < f.min || uint(v) > f.max {
+		return 0, fmt.Errorf("value %d out of range [%d,%d]", v, f.min, f.max)
+	}
+	return uint(v), nil
+}
+
+// Next 从t的下一秒开始，由月到秒逐级寻找满足条件的时间，5年内无匹配时返回零值
+func (cs *cronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Add(time.Second - time.Duration(t.Nanosecond()))
+	limit := t.Year() + 5
+
+wrap:
+	if t.Year() > limit {
+		return time.Time{}
+	}
+	for cs.month&(1<<uint(t.Month())) == 0 {
+		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+	for !cs.dayMatches(t) {
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+	// 时/分/秒按绝对时间推进：夏令时切换当天 time.Date 会把不存在的时刻(如02:00)折回前一小时，按字段推进会原地打转
+	for day := t.Day(); cs.hour&(1<<uint(t.Hour())) == 0; {
+		t = t.Add(time.Hour - time.Duration(t.Minute())*time.Minute - time.Duration(t.Second())*time.Second)
+		if t.Day() != day {
+			goto wrap
+		}
+	}
+	for cs.minute&(1<<uint(t.Minute())) == 0 {
+		t = t.Add(time.Minute - time.Duration(t.Second())*time.Second)
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+	for cs.second&(1<<uint(t.Second())) == 0 {
+		t = t.Add(time.Second)
+		if t.Second() == 0 {
+			goto wrap
+		}
+	}
+	return t
+}
+
+// dayMatches 日与周均非*时满足其一即可，否则需同时满足
+func (cs *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := cs.dom&(1<<uint(t.Day())) > 0
+	dowMatch := cs.dow&(1<<uint(t.Weekday())) > 0
+	if cs.domStar || cs.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// MustParseCron 同 ParseCron，表达式非法时panic，适用于常量表达式
+func MustParseCron(expr string) Schedule {
+	s, err := ParseCron(expr)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
@@ -0,0 +1,104 @@
+package delayQueue
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_ParseCron(t *testing.T) {
+	base := time.Date(2024, 3, 1, 9, 29, 30, 0, time.Local) // 周五
+	cases := []struct {
+		expr   string
+		expect []time.Time
+	}{
+		{"*/5 * * * *", []time.Time{
+			time.Date(2024, 3, 1, 9, 30, 0, 0, time.Local),
+			time.Date(2024, 3, 1, 9, 35, 0, 0, time.Local),
+		}},
+		{"30 9 * * mon-fri", []time.Time{
+			time.Date(2024, 3, 1, 9, 30, 0, 0, time.Local),
+			time.Date(2024, 3, 4, 9, 30, 0, 0, time.Local),
+		}},
+		{"0 0 0 29 feb *", []time.Time{
+			time.Date(2028, 2, 29, 0, 0, 0, 0, time.Local),
+		}},
+		{"15,45 30 9 * * 7", []time.Time{
+			time.Date(2024, 3, 3, 9, 30, 15, 0, time.Local),
+			time.Date(2024, 3, 3, 9, 30, 45, 0, time.Local),
+		}},
+		{"0 12 1 * 1", []time.Time{ // 日与周同时指定时满足其一
+			time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local),
+			time.Date(2024, 3, 4, 12, 0, 0, 0, time.Local),
+		}},
+	}
+	for _, c := range cases {
+		schedule, err := ParseCron(c.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", c.expr, err)
+		}
+		next := base
+		for _, expect := range c.expect {
+			if next = schedule.Next(next); !next.Equal(expect) {
+				t.Fatalf("%s: expect %s got %s", c.expr, expect, next)
+			}
+		}
+	}
+	for _, expr := range []string{"* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "* * * foo *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Fatalf("%s: expect error", expr)
+		}
+	}
+}
+
+func Test_CronDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	cases := []struct {
+		expr   string
+		base   time.Time
+		expect time.Time
+	}{
+		// 2024-03-10 02:00 夏令时开始，02:00-03:00 不存在
+		{"0 9 * * *", time.Date(2024, 3, 9, 12, 0, 0, 0, loc), time.Date(2024, 3, 10, 9, 0, 0, 0, loc)},
+		{"30 2 * * *", time.Date(2024, 3, 9, 12, 0, 0, 0, loc), time.Date(2024, 3, 11, 2, 30, 0, 0, loc)},
+		{"0 * * * *", time.Date(2024, 3, 10, 1, 30, 0, 0, loc), time.Date(2024, 3, 10, 3, 0, 0, 0, loc)},
+		// 2024-11-03 02:00 夏令时结束，01:00-02:00 重复一次
+		{"0 9 * * *", time.Date(2024, 11, 2, 12, 0, 0, 0, loc), time.Date(2024, 11, 3, 9, 0, 0, 0, loc)},
+	}
+	for _, c := range cases {
+		done := make(chan time.Time, 1)
+		go func() { done <- MustParseCron(c.expr).Next(c.base) }()
+		select {
+		case next := <-done:
+			if !next.Equal(c.expect) {
+				t.Fatalf("%s: expect %s got %s", c.expr, c.expect, next)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s: Next from %s did not return", c.expr, c.base)
+		}
+	}
+}
+
+func Test_RecurringUntilAndDelete(t *testing.T) {
+	now := time.Now()
+	msg := NewMessageItem("job", "", now, WithSchedule(Every(time.Minute)), WithUntil(now.Add(90*time.Second)))
+	next := msg.next(now)
+	if next == nil || !next.Time().Equal(now.Add(time.Minute)) || next.Occurrence() != 2 {
+		t.Fatalf("expect second occurrence got %v", next)
+	}
+	if next.next(now) != nil {
+		t.Fatal("expect no occurrence after until")
+	}
+	// 删除即停止后续触发
+	q := NewDelayQe[string]()
+	defer q.Close()
+	_ = q.Add(NewMessageItem("job", "", time.Time{}, WithSchedule(MustParseCron("* * * * * *"))))
+	if err := q.Delete("job"); err != nil {
+		t.Fatal(err)
+	}
+	if q.Len() != 0 {
+		t.Fatalf("expect empty queue got %d", q.Len())
+	}
+}
@@ -0,0 +1,174 @@
+package delayQueue
+
+import (
+	"sort"
+	"time"
+)
+
+// MetricsHook 指标回调，用于将队列指标导出到监控系统
+//
+// 回调在队列锁内同步执行，实现需尽快返回，且不能再调用队列的方法。
+// key为消息在队列中的唯一标识，租户消息包含租户名，可通过 SplitKey 拆分
+type MetricsHook interface {
+	OnAdd(key string)                     // 添加成功
+	OnDuplicate(key string)               // 添加时key重复
+	OnDelete(key string)                  // 主动删除
+	OnFire(key string, lag time.Duration) // 触发，lag为实际触发时间与过期时间的差值
+}
+
+// lagBuckets 触发延迟直方图的桶上界
+var lagBuckets = []time.Duration{
+	time.Millisecond, 5 * time.Millisecond, 10 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 500 * time.Millisecond, time.Second, 5 * time.Second, 10 * time.Second, time.Minute,
+}
+
+// LagHistogram 触发延迟分布
+type LagHistogram struct {
+	Bounds []time.Duration // 各桶上界(含)
+	Counts []uint64        // 各桶计数，比Bounds多一个桶，记录超过最大上界的次数
+	Sum    time.Duration   // 延迟总和
+	Max    time.Duration   // 最大延迟
+}
+
+func newLagHistogram() LagHistogram {
+	return LagHistogram{
+		Bounds: lagBuckets,
+		Counts: make([]uint64, len(lagBuckets)+1),
+	}
+}
+
+// observe 记录一次触发延迟
+func (h *LagHistogram) observe(lag time.Duration) {
+	if lag < 0 {
+		lag = 0
+	}
+	h.Counts[sort.Search(len(h.Bounds), func(i int) bool { return lag <= h.Bounds[i] })]++
+	h.Sum += lag
+	if lag > h.Max {
+		h.Max = lag
+	}
+}
+
+// Total 记录的触发次数
+func (h LagHistogram) Total() uint64 {
+	var total uint64
+	for _, c := range h.Counts {
+		total += c
+	}
+	return total
+}
+
+// Mean 平均触发延迟
+func (h LagHistogram) Mean() time.Duration {
+	total := h.Total()
+	if total == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(total)
+}
+
+// clone 拷贝直方图，避免调用方读取时与队列并发修改
+func (h LagHistogram) clone() LagHistogram {
+	h.Counts = append([]uint64(nil), h.Counts...)
+	return h
+}
+
+// Stats 队列统计信息
+type Stats struct {
+	Pending   int          // 待触发数量
+	Earliest  time.Time    // 最早的过期时间，队列为空时为零值
+	Latest    time.Time    // 最晚的过期时间，队列为空时为零值
+	Added     uint64       // 累计添加数量
+	Fired     uint64       // 累计触发数量
+	Deleted   uint64       // 累计主动删除数量
+	Duplicate uint64       // 累计key重复的添加次数
+	Evicted   uint64       // 累计因容量上限被移除的数量
+	Lag       LagHistogram // 触发延迟分布
+}
+
+// queueStats 队列内部累计的计数，由队列锁保护
+type queueStats struct {
+	added, fired, deleted, duplicate, evicted uint64
+	lag                                       LagHistogram
+	hook                                      MetricsHook
+}
+
+func newQueueStats(hook MetricsHook) queueStats {
+	return queueStats{lag: newLagHistogram(), hook: hook}
+}
+
+func (s *queueStats) onAdd(key string) {
+	s.added++
+	if s.hook != nil {
+		s.hook.OnAdd(key)
+	}
+}
+
+func (s *queueStats) onDuplicate(key string) {
+	s.duplicate++
+	if s.hook != nil {
+		s.hook.OnDuplicate(key)
+	}
+}
+
+func (s *queueStats) onDelete(key string) {
+	s.deleted++
+	if s.hook != nil {
+		s.hook.OnDelete(key)
+	}
+}
+
+func (s *queueStats) onFire(key string, lag time.Duration) {
+	s.fired++
+	s.lag.observe(lag)
+	if s.hook != nil {
+		s.hook.OnFire(key, lag)
+	}
+}
+
+// Stats 获取队列统计信息，最晚过期时间只需遍历堆的叶子节点，复杂度O(n/2)
+func (hd *HeapDelayQueue[T]) Stats() Stats {
+	hd.lock.RLock()
+	defer hd.lock.RUnlock()
+	st := Stats{
+		Pending:   len(hd.m),
+		Added:     hd.stats.added,
+		Fired:     hd.stats.fired,
+		Deleted:   hd.stats.deleted,
+		Duplicate: hd.stats.duplicate,
+		Evicted:   hd.stats.evicted,
+		Lag:       hd.stats.lag.clone(),
+	}
+	if len(hd.m) > 0 {
+		st.Earliest = hd.heap.Get(0).sec
+		st.Latest = hd.furthest().sec
+	}
+	return st
+}
+
+// Range 按触发顺序遍历过期时间在[from, to)之间的待触发任务，fn返回false时停止遍历
+//
+// from/to 传零值表示不限制。遍历的是调用时刻的快照，fn中可以安全地调用队列的其他方法
+func (hd *HeapDelayQueue[T]) Range(from, to time.Time, fn func(msg *MessageItem[T]) bool) {
+	for _, msg := range hd.List(from, to) {
+		if !fn(msg) {
+			return
+		}
+	}
+}
+
+// List 按触发顺序返回过期时间在[from, to)之间的待触发任务，from/to 传零值表示不限制
+func (hd *HeapDelayQueue[T]) List(from, to time.Time) []*MessageItem[T] {
+	hd.lock.RLock()
+	defer hd.lock.RUnlock()
+	res := make([]*MessageItem[T], 0)
+	for _, msg := range hd.m {
+		if (from.IsZero() || !msg.sec.Before(from)) && (to.IsZero() || msg.sec.Before(to)) {
+			res = append(res, msg)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].before(res[j])
+	})
+	return res
+}
@@ -0,0 +1,114 @@
+package delayQueue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordHook 记录所有回调的指标实现
+type recordHook struct {
+	mu     sync.Mutex
+	events []string
+	lags   []time.Duration
+}
+
+func (r *recordHook) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recordHook) OnAdd(key string)       { r.record("add:" + key) }
+func (r *recordHook) OnDuplicate(key string) { r.record("duplicate:" + key) }
+func (r *recordHook) OnDelete(key string)    { r.record("delete:" + key) }
+func (r *recordHook) OnFire(key string, lag time.Duration) {
+	r.record("fire:" + key)
+	r.mu.Lock()
+	r.lags = append(r.lags, lag)
+	r.mu.Unlock()
+}
+
+func Test_Stats(t *testing.T) {
+	hook := &recordHook{}
+	hd := NewDelayQe[string](WithMetrics(hook))
+	defer hd.Close()
+	now := time.Now()
+	_ = hd.Add(NewMessageItem("k1", "", now.Add(-time.Second)))
+	_ = hd.Add(NewMessageItem("k2", "", now.Add(time.Hour)))
+	_ = hd.Add(NewMessageItem("k3", "", now.Add(2*time.Hour)))
+	_ = hd.Add(NewMessageItem("k4", "", now.Add(3*time.Hour)))
+	_ = hd.Add(NewMessageItem("k2", "", now))
+	_ = hd.Delete("k4")
+	if _, err := hd.Watch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	st := hd.Stats()
+	if st.Pending != 2 || st.Added != 4 || st.Fired != 1 || st.Deleted != 1 || st.Duplicate != 1 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+	if !st.Earliest.Equal(now.Add(time.Hour)) || !st.Latest.Equal(now.Add(2*time.Hour)) {
+		t.Fatalf("unexpected earliest/latest %s %s", st.Earliest, st.Latest)
+	}
+	if st.Lag.Total() != 1 || st.Lag.Max < time.Second || st.Lag.Counts[len(lagBuckets)-3] != 1 {
+		t.Fatalf("unexpected lag histogram %+v", st.Lag)
+	}
+	expect := []string{"add:k1", "add:k2", "add:k3", "add:k4", "duplicate:k2", "delete:k4", "fire:k1"}
+	if len(hook.events) != len(expect) {
+		t.Fatalf("expect events %v got %v", expect, hook.events)
+	}
+	for i := range expect {
+		if hook.events[i] != expect[i] {
+			t.Fatalf("expect events %v got %v", expect, hook.events)
+		}
+	}
+}
+
+func Test_StatsTenantKey(t *testing.T) {
+	hook := &recordHook{}
+	tq := NewTenantDelayQe[string](NewDelayQe[string](WithMetrics(hook)))
+	defer tq.Close()
+	_ = tq.Tenant("a").Add(NewMessageItem("k1", "", time.Now().Add(-time.Second)))
+	_ = tq.Tenant("b").Add(NewMessageItem("k1", "", time.Now().Add(time.Hour)))
+	if _, err := tq.Watch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// 不同租户的同名key在指标中可区分
+	expect := []string{"add:a\x00k1", "add:b\x00k1", "fire:a\x00k1"}
+	if len(hook.events) != len(expect) {
+		t.Fatalf("expect events %v got %q", expect, hook.events)
+	}
+	for i := range expect {
+		if hook.events[i] != expect[i] {
+			t.Fatalf("expect events %v got %q", expect, hook.events)
+		}
+	}
+	if tenant, key := SplitKey(hook.events[1][len("add:"):]); tenant != "b" || key != "k1" {
+		t.Fatalf("unexpected split %s %s", tenant, key)
+	}
+	if tenant, key := SplitKey("k1"); tenant != "" || key != "k1" {
+		t.Fatalf("unexpected split %s %s", tenant, key)
+	}
+}
+
+func Test_Range(t *testing.T) {
+	hd := NewDelayQe[int]()
+	defer hd.Close()
+	now := time.Now()
+	for i := 5; i > 0; i-- {
+		_ = hd.Add(NewMessageItem(string(rune('a'+i)), i, now.Add(time.Duration(i)*time.Minute)))
+	}
+	list := hd.List(now.Add(2*time.Minute), now.Add(5*time.Minute))
+	if len(list) != 3 || list[0].Content() != 2 || list[2].Content() != 4 {
+		t.Fatalf("unexpected list %v", list)
+	}
+	var visited []int
+	hd.Range(time.Time{}, time.Time{}, func(msg *MessageItem[int]) bool {
+		visited = append(visited, msg.Content())
+		return len(visited) < 3
+	})
+	if len(visited) != 3 || visited[0] != 1 || visited[2] != 3 {
+		t.Fatalf("unexpected range %v", visited)
+	}
+}
@@ -0,0 +1,127 @@
+package delayQueue
+
+import (
+	"context"
+	"errors"
+)
+
+// Backpressure 订阅通道缓冲区满时的处理策略
+type Backpressure int
+
+const (
+	BlockOnFull Backpressure = iota // 阻塞等待消费，期间不再取出新的到期消息(默认)
+	DropNewest                      // 丢弃刚到期的消息
+	DropOldest                      // 丢弃缓冲区中最早的消息，再放入刚到期的消息
+)
+
+// SubscribeOption Subscribe的配置项
+type SubscribeOption[T messageTyps] func(s *subscriber[T])
+
+// WithBuffer 订阅通道的缓冲区大小，默认为0(无缓冲)
+func WithBuffer[T messageTyps](size int) SubscribeOption[T] {
+	return func(s *subscriber[T]) {
+		if size > 0 {
+			s.buffer = size
+		}
+	}
+}
+
+// WithBackpressure 缓冲区满时的处理策略
+//
+// @param onDrop 消息被丢弃时的回调，可传nil
+func WithBackpressure[T messageTyps](policy Backpressure, onDrop func(msg *MessageItem[T])) SubscribeOption[T] {
+	return func(s *subscriber[T]) {
+		s.policy = policy
+		s.onDrop = onDrop
+	}
+}
+
+// subscriber 由单个协程循环调用Watch，将到期消息写入订阅通道
+type subscriber[T messageTyps] struct {
+	buffer int                   // 通道缓冲区大小
+	policy Backpressure          // 缓冲区满时的策略
+	onDrop func(*MessageItem[T]) // 丢弃回调
+}
+
+// subscribe 启动订阅协程，ctx结束或队列关闭(done)时关闭通道
+func subscribe[T messageTyps](ctx context.Context, done <-chan struct{},
+	watch func(ctx context.Context) (*MessageItem[T], error), opts []SubscribeOption[T]) <-chan *MessageItem[T] {
+	s := &subscriber[T]{}
+	for _, opt := range opts {
+		opt(s)
+	}
+	ch := make(chan *MessageItem[T], s.buffer)
+	go func() {
+		defer close(ch)
+		for {
+			msg, err := watch(ctx)
+			if err != nil {
+				if errors.Is(err, EmptyQueue) { // 定时器触发时任务已被删除，继续等待
+					continue
+				}
+				return
+			}
+			if !s.send(ctx, done, ch, msg) {
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// send 按背压策略写入通道，ctx结束或队列关闭时返回false
+func (s *subscriber[T]) send(ctx context.Context, done <-chan struct{}, ch chan *MessageItem[T], msg *MessageItem[T]) bool {
+	switch s.policy {
+	case DropNewest:
+		select {
+		case ch <- msg:
+		default:
+			s.drop(msg)
+		}
+		return true
+	case DropOldest:
+		for {
+			select {
+			case ch <- msg:
+				return true
+			default:
+			}
+			select { // 缓冲区满，丢弃最早的一条后重试；无缓冲时等同于DropNewest
+			case old := <-ch:
+				s.drop(old)
+			default:
+				if s.buffer == 0 {
+					s.drop(msg)
+					return true
+				}
+			}
+		}
+	default:
+		select {
+		case ch <- msg:
+			return true
+		case <-ctx.Done():
+		case <-done:
+		}
+		return false
+	}
+}
+
+func (s *subscriber[T]) drop(msg *MessageItem[T]) {
+	if s.onDrop != nil {
+		s.onDrop(msg)
+	}
+}
+
+// Subscribe 订阅到期消息，便于与其他通道一起select
+//
+// 通道在ctx结束或队列关闭时关闭。同一个队列的多个订阅者(或与Watch/Run并存时)竞争消费，每条消息只会投递一次；
+// BlockOnFull策略下，已到期但因ctx结束或队列关闭未能投递的消息会被丢弃。
+func (hd *HeapDelayQueue[T]) Subscribe(ctx context.Context, opts ...SubscribeOption[T]) <-chan *MessageItem[T] {
+	return subscribe(ctx, hd.done, hd.Watch, opts)
+}
+
+// Subscribe 订阅到期消息，语义同 HeapDelayQueue.Subscribe
+func (tw *TimingWheelDelayQueue[T]) Subscribe(ctx context.Context, opts ...SubscribeOption[T]) <-chan *MessageItem[T] {
+	return subscribe(ctx, tw.done, tw.Watch, opts)
+}
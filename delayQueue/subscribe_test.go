@@ -0,0 +1,77 @@
+package delayQueue_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"git.woa.com/kf_cdms/go-public/delayQueue"
+)
+
+func Test_Subscribe(t *testing.T) {
+	for name, backend := range backends {
+		backend := backend
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			clock := newFakeClock()
+			q := delayQueue.New[string](delayQueue.WithBackend(backend), delayQueue.WithClock(clock)).(interface {
+				delayQueue.DelayQueue[string]
+				Subscribe(ctx context.Context, opts ...delayQueue.SubscribeOption[string]) <-chan *delayQueue.MessageItem[string]
+			})
+			now := clock.Now()
+			_ = q.Add(delayQueue.NewMessageItem("k2", "v2", now.Add(2*time.Second)))
+			_ = q.Add(delayQueue.NewMessageItem("k1", "v1", now))
+			ch := q.Subscribe(context.Background(), delayQueue.WithBuffer[string](1))
+			for i, key := range []string{"k1", "k2"} {
+				if i > 0 {
+					advance(clock, 2*time.Second)
+				}
+				select {
+				case msg := <-ch:
+					if msg.Key() != key {
+						t.Fatalf("expect %s got %s", key, msg.Key())
+					}
+				case <-time.After(3 * time.Second):
+					t.Fatalf("timeout waiting %s", key)
+				}
+			}
+			q.Close()
+			if _, ok := <-ch; ok {
+				t.Fatal("expect channel closed after queue closed")
+			}
+		})
+	}
+}
+
+func Test_SubscribeBackpressure(t *testing.T) {
+	for _, tc := range []struct {
+		policy delayQueue.Backpressure
+		expect string
+	}{
+		{delayQueue.DropNewest, "k1"},
+		{delayQueue.DropOldest, "k3"},
+	} {
+		hd := delayQueue.NewDelayQe[string]()
+		now := time.Now()
+		for i, key := range []string{"k1", "k2", "k3"} {
+			_ = hd.Add(delayQueue.NewMessageItem(key, key, now.Add(time.Duration(i)*time.Millisecond)))
+		}
+		var dropped atomic.Int32
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := hd.Subscribe(ctx, delayQueue.WithBuffer[string](1),
+			delayQueue.WithBackpressure(tc.policy, func(msg *delayQueue.MessageItem[string]) { dropped.Add(1) }))
+		deadline := time.Now().Add(3 * time.Second)
+		for dropped.Load() < 2 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if msg := <-ch; dropped.Load() != 2 || msg.Key() != tc.expect {
+			t.Fatalf("policy %d: expect %s with 2 dropped, got %s with %d", tc.policy, tc.expect, msg.Key(), dropped.Load())
+		}
+		cancel()
+		if _, ok := <-ch; ok {
+			t.Fatal("expect channel closed after ctx done")
+		}
+		hd.Close()
+	}
+}
@@ -0,0 +1,314 @@
+package delayQueue
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// TenantDelayQueue 多租户延迟队列
+//
+// 多个租户(命名空间)共享同一个底层队列，key只需在租户内唯一。支持按租户限制待触发消息数、统计、暂停与清除。
+// 租户计数保存在内存中，底层队列为持久化队列时，重启恢复的消息不计入租户计数；
+// 暂停期间到期、等待恢复的消息同样只保存在内存中，重启后会丢失。
+type TenantDelayQueue[T messageTyps] struct {
+	q       DelayQueue[T]              // 底层延迟队列
+	tenants map[string]*tenantState[T] // 租户名 -> 租户状态
+	limit   int                        // 新租户默认的容量上限
+	lock    sync.Mutex
+}
+
+// tenantState 租户状态，由队列锁保护
+type tenantState[T messageTyps] struct {
+	keys     map[string]struct{} // 底层队列中待触发消息的id
+	held     []*MessageItem[T]   // 暂停期间到期、等待恢复后投递的消息，每个id至多一条
+	paused   bool
+	limit    int
+	added    uint64
+	fired    uint64
+	deleted  uint64
+	rejected uint64
+}
+
+// TenantStats 租户统计信息
+type TenantStats struct {
+	Pending  int    // 待触发数量(含暂停期间已到期、等待恢复的消息)
+	Held     int    // 暂停期间已到期、等待恢复的消息数量
+	Paused   bool   // 是否已暂停
+	Limit    int    // 容量上限，<=0表示不限
+	Added    uint64 // 累计添加数量
+	Fired    uint64 // 累计投递数量
+	Deleted  uint64 // 累计删除/清除数量
+	Rejected uint64 // 累计因容量上限被拒绝的添加次数
+}
+
+// Tenant 单个租户的操作句柄
+type Tenant[T messageTyps] struct {
+	tq   *TenantDelayQueue[T]
+	name string
+}
+
+// NewTenantDelayQe 在已有延迟队列之上开启多租户模式
+//
+// @param q 底层延迟队列，多租户模式接管后不应再直接调用其Add/Delete/Watch
+func NewTenantDelayQe[T messageTyps](q DelayQueue[T], opts ...Option) *TenantDelayQueue[T] {
+	o := newOptions(opts)
+	return &TenantDelayQueue[T]{
+		q:       q,
+		tenants: make(map[string]*tenantState[T]),
+		limit:   o.tenantLimit,
+	}
+}
+
+// Tenant 获取租户句柄，租户不存在时自动创建
+func (tq *TenantDelayQueue[T]) Tenant(name string) *Tenant[T] {
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+	tq.state(name)
+	return &Tenant[T]{tq: tq, name: name}
+}
+
+// Tenants 按名称排序返回全部租户
+func (tq *TenantDelayQueue[T]) Tenants() []string {
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+	names := make([]string, 0, len(tq.tenants))
+	for name := range tq.tenants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Len 全部租户待触发的任务数量
+func (tq *TenantDelayQueue[T]) Len() int {
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+	n := 0
+	for _, ts := range tq.tenants {
+		n += ts.pending()
+	}
+	return n
+}
+
+// Watch 监听全部租户的到期消息，通过 MessageItem.Tenant 区分所属租户。已暂停租户的消息暂存至恢复
+func (tq *TenantDelayQueue[T]) Watch(ctx context.Context) (*MessageItem[T], error) {
+	for {
+		msg, err := tq.q.Watch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if tq.fired(msg) {
+			return msg, nil
+		}
+	}
+}
+
+// fired 更新租户计数，租户已暂停时暂存消息并返回false
+func (tq *TenantDelayQueue[T]) fired(msg *MessageItem[T]) bool {
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+	ts := tq.state(msg.tenant)
+	id := msg.id()
+	// 周期消息的下一次触发已由底层队列以同一个id加入，仍然处于待触发状态
+	if next, _ := tq.q.Search(id); next == nil {
+		delete(ts.keys, id)
+	}
+	if ts.paused {
+		// 周期消息暂停期间多次到期时只保留最近一次，恢复后至多补发一次
+		if i := ts.heldIndex(msg.key); i >= 0 {
+			ts.held[i] = msg
+		} else {
+			ts.held = append(ts.held, msg)
+		}
+		return false
+	}
+	ts.fired++
+	return true
+}
+
+// Close 关闭底层队列
+func (tq *TenantDelayQueue[T]) Close() {
+	tq.q.Close()
+}
+
+func (tq *TenantDelayQueue[T]) state(name string) *tenantState[T] {
+	ts, ok := tq.tenants[name]
+	if !ok {
+		ts = &tenantState[T]{keys: make(map[string]struct{}), limit: tq.limit}
+		tq.tenants[name] = ts
+	}
+	return ts
+}
+
+// heldIndex 暂存消息中key的下标，不存在时返回-1
+func (ts *tenantState[T]) heldIndex(key string) int {
+	for i, msg := range ts.held {
+		if msg.key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// pending 待触发的不同id数量。暂存的周期消息的下一次触发同时在keys中，只计一次
+func (ts *tenantState[T]) pending() int {
+	n := len(ts.keys)
+	for _, msg := range ts.held {
+		if _, ok := ts.keys[msg.id()]; !ok {
+			n++
+		}
+	}
+	return n
+}
+
+// Name 租户名
+func (t *Tenant[T]) Name() string {
+	return t.name
+}
+
+// Add 添加任务，消息的租户会被设置为当前租户。待触发消息数达到上限时返回TenantFullError，
+// key已存在(含暂停期间已到期、等待恢复的消息)时返回RepeatError
+func (t *Tenant[T]) Add(msg *MessageItem[T]) error {
+	tq := t.tq
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+	ts := tq.state(t.name)
+	if ts.heldIndex(msg.key) >= 0 {
+		return RepeatError
+	}
+	if ts.limit > 0 && ts.pending() >= ts.limit {
+		ts.rejected++
+		return TenantFullError
+	}
+	msg.tenant = t.name
+	if err := tq.q.Add(msg); err != nil {
+		return err
+	}
+	ts.keys[msg.id()] = struct{}{}
+	ts.added++
+	return nil
+}
+
+// Delete 移除任务(含暂停期间已到期、等待恢复的消息)
+func (t *Tenant[T]) Delete(key string) error {
+	tq := t.tq
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+	ts := tq.state(t.name)
+	id := scopedKey(t.name, key)
+	i := ts.heldIndex(key)
+	held := i >= 0
+	if held {
+		ts.held = append(ts.held[:i], ts.held[i+1:]...)
+	}
+	// 周期消息暂存期间，下一次触发已由底层队列以同一个id加入，需要一并移除
+	if err := tq.q.Delete(id); err != nil && !held {
+		return err
+	}
+	delete(ts.keys, id)
+	ts.deleted++
+	return nil
+}
+
+// Search 查询任务，不存在时返回nil
+func (t *Tenant[T]) Search(key string) (*MessageItem[T], error) {
+	tq := t.tq
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+	ts := tq.state(t.name)
+	if i := ts.heldIndex(key); i >= 0 {
+		return ts.held[i], nil
+	}
+	return tq.q.Search(scopedKey(t.name, key))
+}
+
+// Len 租户待触发的任务数量
+func (t *Tenant[T]) Len() int {
+	tq := t.tq
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+	return tq.state(t.name).pending()
+}
+
+// SetLimit 设置租户的容量上限，<=0表示不限。已超出上限的消息不受影响，只拒绝新的添加
+func (t *Tenant[T]) SetLimit(n int) {
+	tq := t.tq
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+	tq.state(t.name).limit = n
+}
+
+// Stats 租户统计信息
+func (t *Tenant[T]) Stats() TenantStats {
+	tq := t.tq
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+	ts := tq.state(t.name)
+	return TenantStats{
+		Pending:  ts.pending(),
+		Held:     len(ts.held),
+		Paused:   ts.paused,
+		Limit:    ts.limit,
+		Added:    ts.added,
+		Fired:    ts.fired,
+		Deleted:  ts.deleted,
+		Rejected: ts.rejected,
+	}
+}
+
+// Purge 清除租户的全部消息，返回清除的数量
+func (t *Tenant[T]) Purge() int {
+	tq := t.tq
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+	ts := tq.state(t.name)
+	n := 0
+	for _, msg := range ts.held {
+		if _, ok := ts.keys[msg.id()]; !ok {
+			n++
+		}
+	}
+	ts.held = nil
+	for id := range ts.keys {
+		if tq.q.Delete(id) == nil {
+			n++
+		}
+	}
+	ts.keys = make(map[string]struct{})
+	ts.deleted += uint64(n)
+	return n
+}
+
+// Pause 暂停租户：消息照常到期，但暂存至 Resume 后再投递，期间仍可添加消息
+func (t *Tenant[T]) Pause() {
+	tq := t.tq
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+	tq.state(t.name).paused = true
+}
+
+// Resume 恢复租户，暂停期间到期的消息按原触发顺序立即投递
+//
+// 周期消息暂停期间的下一次触发已在队列中时，错过的触发由其取代，不再补发。
+// 重新入队失败的消息记录在返回的 *BatchError 中
+func (t *Tenant[T]) Resume() error {
+	tq := t.tq
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+	ts := tq.state(t.name)
+	ts.paused = false
+	failed := make(map[string]error)
+	for _, msg := range ts.held {
+		if _, ok := ts.keys[msg.id()]; ok {
+			continue
+		}
+		if err := tq.q.Add(msg); err != nil {
+			failed[msg.key] = err
+			continue
+		}
+		ts.keys[msg.id()] = struct{}{}
+	}
+	ts.held = nil
+	return batchResult(failed)
+}
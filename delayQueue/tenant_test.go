@@ -0,0 +1,212 @@
+package delayQueue_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"git.woa.com/kf_cdms/go-public/delayQueue"
+)
+
+func Test_TenantDelayQueue(t *testing.T) {
+	clock := newFakeClock()
+	tq := delayQueue.NewTenantDelayQe[string](delayQueue.NewDelayQe[string](delayQueue.WithClock(clock)), delayQueue.WithTenantLimit(2))
+	defer tq.Close()
+	a, b := tq.Tenant("a"), tq.Tenant("b")
+	now := clock.Now()
+	if err := a.Add(delayQueue.NewMessageItem("k1", "a1", now.Add(-2*time.Second))); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Add(delayQueue.NewMessageItem("k2", "a2", now.Add(-3*time.Second))); err != nil {
+		t.Fatal(err)
+	}
+	// 不同租户的key可以重复，同一租户内不可重复
+	if err := b.Add(delayQueue.NewMessageItem("k1", "b1", now.Add(-time.Second))); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Add(delayQueue.NewMessageItem("k1", "b1", now)); !errors.Is(err, delayQueue.RepeatError) {
+		t.Fatalf("expect RepeatError got %v", err)
+	}
+	if err := a.Add(delayQueue.NewMessageItem("k3", "a3", now)); !errors.Is(err, delayQueue.TenantFullError) {
+		t.Fatalf("expect TenantFullError got %v", err)
+	}
+	if msg, _ := b.Search("k1"); msg == nil || msg.Content() != "b1" || msg.Tenant() != "b" {
+		t.Fatalf("unexpected search result %v", msg)
+	}
+
+	// 暂停的租户消息到期后暂存，不影响其他租户
+	a.Pause()
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	msg, err := tq.Watch(ctx)
+	if err != nil || msg.Content() != "b1" {
+		t.Fatalf("expect b1 got %v %v", msg, err)
+	}
+	if st := a.Stats(); !st.Paused || st.Held != 2 || st.Pending != 2 || st.Rejected != 1 || tq.Len() != 2 {
+		t.Fatalf("unexpected stats %+v len %d", st, tq.Len())
+	}
+	if err = a.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []string{"a2", "a1"} {
+		if msg, err = tq.Watch(ctx); err != nil || msg.Content() != expect || msg.Tenant() != "a" {
+			t.Fatalf("expect %s got %v %v", expect, msg, err)
+		}
+	}
+	if st := a.Stats(); st.Fired != 2 || st.Pending != 0 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+
+	_ = b.Add(delayQueue.NewMessageItem("k2", "b2", now.Add(time.Hour)))
+	_ = b.Add(delayQueue.NewMessageItem("k3", "b3", now.Add(time.Hour)))
+	_ = a.Add(delayQueue.NewMessageItem("k2", "a2", now.Add(time.Hour)))
+	if n := b.Purge(); n != 2 || b.Len() != 0 || a.Len() != 1 {
+		t.Fatalf("expect purge 2 got %d, left a %d b %d", n, a.Len(), b.Len())
+	}
+	if err = a.Delete("k2"); err != nil || a.Len() != 0 || tq.Len() != 0 {
+		t.Fatalf("expect empty after delete got %v %d", err, tq.Len())
+	}
+	if names := tq.Tenants(); len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Fatalf("unexpected tenants %v", names)
+	}
+}
+
+// watchPaused 在后台调用Watch，使暂停租户的到期消息进入暂存，返回停止函数
+func watchPaused(tq *delayQueue.TenantDelayQueue[string]) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = tq.Watch(ctx)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// awaitHeld 等待租户暂存第occurrence次触发的key
+func awaitHeld(tenant *delayQueue.Tenant[string], key string, occurrence int) {
+	for {
+		msg, _ := tenant.Search(key)
+		if tenant.Stats().Held == 1 && msg != nil && msg.Occurrence() == occurrence {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func Test_TenantDeletePausedRecurring(t *testing.T) {
+	clock := newFakeClock()
+	tq := delayQueue.NewTenantDelayQe[string](delayQueue.NewDelayQe[string](delayQueue.WithClock(clock)))
+	defer tq.Close()
+	a := tq.Tenant("a")
+	_ = a.Add(delayQueue.NewMessageItem("job", "job", clock.Now().Add(-time.Second), delayQueue.WithSchedule(delayQueue.Every(time.Hour))))
+	a.Pause()
+	stop := watchPaused(tq)
+	awaitHeld(a, "job", 1)
+	stop()
+	// 暂存的本次触发与底层队列中的下一次触发都应移除
+	if err := a.Delete("job"); err != nil {
+		t.Fatal(err)
+	}
+	if msg, _ := a.Search("job"); msg != nil || a.Len() != 0 || tq.Len() != 0 {
+		t.Fatalf("expect job deleted got %v len %d", msg, tq.Len())
+	}
+	if err := a.Resume(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_TenantPausedRecurringPileUp(t *testing.T) {
+	clock := newFakeClock()
+	hd := delayQueue.NewDelayQe[string](delayQueue.WithClock(clock))
+	tq := delayQueue.NewTenantDelayQe[string](hd, delayQueue.WithTenantLimit(2))
+	defer tq.Close()
+	a := tq.Tenant("a")
+	_ = a.Add(delayQueue.NewMessageItem("job", "job", clock.Now(), delayQueue.WithSchedule(delayQueue.Every(time.Second))))
+	a.Pause()
+	stop := watchPaused(tq)
+	// 暂停期间连续到期3次，只暂存最近一次
+	for occurrence := 1; occurrence <= 3; occurrence++ {
+		if occurrence > 1 {
+			clock.Advance(time.Second)
+		}
+		awaitHeld(a, "job", occurrence)
+	}
+	stop()
+	// 暂存与下一次触发属于同一个id，只计一次
+	if st := a.Stats(); st.Held != 1 || st.Pending != 1 || a.Len() != 1 || tq.Len() != 1 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+	if err := a.Add(delayQueue.NewMessageItem("job", "job", clock.Now())); !errors.Is(err, delayQueue.RepeatError) {
+		t.Fatalf("expect RepeatError got %v", err)
+	}
+	if err := a.Add(delayQueue.NewMessageItem("other", "other", clock.Now().Add(time.Hour))); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Delete("job"); err != nil {
+		t.Fatal(err)
+	}
+	if msg, _ := a.Search("job"); msg != nil || a.Len() != 1 {
+		t.Fatalf("expect job deleted got %v len %d", msg, a.Len())
+	}
+	if err := a.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	// 删除后不再有后续触发
+	if msg := hd.Peek(); msg == nil || msg.Key() != "other" || hd.Len() != 1 {
+		t.Fatalf("expect only other queued got %v len %d", msg, hd.Len())
+	}
+}
+
+func Test_TenantResumeRecurring(t *testing.T) {
+	clock := newFakeClock()
+	tq := delayQueue.NewTenantDelayQe[string](delayQueue.NewDelayQe[string](delayQueue.WithClock(clock)))
+	defer tq.Close()
+	a := tq.Tenant("a")
+	_ = a.Add(delayQueue.NewMessageItem("job", "job", clock.Now(), delayQueue.WithSchedule(delayQueue.Every(time.Second))))
+	a.Pause()
+	stop := watchPaused(tq)
+	awaitHeld(a, "job", 1)
+	stop()
+	// 下一次触发已在队列中，错过的触发被取代，恢复不视为失败
+	if err := a.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	if st := a.Stats(); st.Held != 0 || st.Pending != 1 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+	clock.Advance(time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if msg, err := tq.Watch(ctx); err != nil || msg.Key() != "job" || msg.Occurrence() != 2 {
+		t.Fatalf("expect second occurrence got %v %v", msg, err)
+	}
+}
+
+func Test_PersistentTenant(t *testing.T) {
+	dir := t.TempDir()
+	pq, err := delayQueue.NewPersistentDelayQe[string](dir, delayQueue.JSONCodec[string]{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tq := delayQueue.NewTenantDelayQe[string](pq)
+	_ = tq.Tenant("a").Add(delayQueue.NewMessageItem("k", "a", time.Now().Add(time.Hour)))
+	_ = tq.Tenant("b").Add(delayQueue.NewMessageItem("k", "b", time.Now().Add(time.Hour)))
+	_ = tq.Tenant("b").Delete("k")
+	tq.Close()
+
+	pq, err = delayQueue.NewPersistentDelayQe[string](dir, delayQueue.JSONCodec[string]{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pq.Close()
+	if pq.Len() != 1 {
+		t.Fatalf("expect 1 recovered got %d", pq.Len())
+	}
+	if msg, _ := delayQueue.NewTenantDelayQe[string](pq).Tenant("a").Search("k"); msg == nil || msg.Content() != "a" || msg.Tenant() != "a" {
+		t.Fatalf("unexpected recovered message %v", msg)
+	}
+}
@@ -0,0 +1,354 @@
+package delayQueue
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// wheelLevel 时间轮单层配置
+type wheelLevel struct {
+	unit  int64 // 每个槽位代表的刻度数(秒)
+	slots int64 // 槽位数量
+}
+
+// 时间轮层级：秒/分/时/天。下一层的总跨度恰好等于上一层的单个槽位跨度
+var wheelLevels = []wheelLevel{
+	{unit: 1, slots: 60},      // 秒轮 60 * 1s
+	{unit: 60, slots: 60},     // 分轮 60 * 1m
+	{unit: 3600, slots: 24},   // 时轮 24 * 1h
+	{unit: 86400, slots: 365}, // 天轮 365 * 1d
+}
+
+// wheelEntry 时间轮中的消息节点，记录所在链表及链表元素，便于O(1)移除
+type wheelEntry[T messageTyps] struct {
+	item *MessageItem[T]
+	list *list.List
+	elem *list.Element
+}
+
+// TimingWheelDelayQueue 延迟队列实现(多层时间轮)
+//
+// 插入与删除复杂度均为O(1)，触发精度为1秒(消息不会早于过期时间触发，最多延后1秒)。
+// 时间轮内部会启动一个推进刻度的协程，不再使用时需调用Close释放。
+type TimingWheelDelayQueue[T messageTyps] struct {
+	m         map[string]*wheelEntry[T] // 用于消息去重/查询，消息key必须是string类型，且唯一
+	wheels    [][]*list.List            // 各层时间轮槽位
+	ready     *list.List                // 已到期、等待Watch消费的消息
+	cur       int64                     // 当前刻度(unix秒)
+	lock      sync.Mutex                // 加把锁
+	notify    chan struct{}             // 有到期消息时通知Watch
+	done      chan struct{}             // 关闭信号
+	closeOnce sync.Once                 // 保证只关闭一次
+	seq       uint64                    // 入队序号
+	clock     Clock                     // 时钟
+}
+
+// NewTimingWheelDelayQe 初始化时间轮延迟队列
+func NewTimingWheelDelayQe[T messageTyps](opts ...Option) *TimingWheelDelayQueue[T] {
+	o := newOptions(opts)
+	tw := newTimingWheelDelayQe[T](o.clock.Now().Unix())
+	tw.clock = o.clock
+	go tw.run()
+	return tw
+}
+
+// newTimingWheelDelayQe 以指定刻度初始化时间轮，不启动推进协程
+func newTimingWheelDelayQe[T messageTyps](cur int64) *TimingWheelDelayQueue[T] {
+	wheels := make([][]*list.List, len(wheelLevels))
+	for i, lv := range wheelLevels {
+		wheels[i] = make([]*list.List, lv.slots)
+		for j := range wheels[i] {
+			wheels[i][j] = list.New()
+		}
+	}
+	return &TimingWheelDelayQueue[T]{
+		m:      make(map[string]*wheelEntry[T]),
+		wheels: wheels,
+		ready:  list.New(),
+		cur:    cur,
+		notify: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+		clock:  realClock{},
+	}
+}
+
+// Add 添加任务
+//
+// @param msg 消息体结构
+func (tw *TimingWheelDelayQueue[T]) Add(msg *MessageItem[T]) error {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+	return tw.add(msg)
+}
+
+func (tw *TimingWheelDelayQueue[T]) add(msg *MessageItem[T]) error {
+	if _, ok := tw.m[msg.id()]; ok {
+		return RepeatError
+	}
+	msg.resolve(tw.clock.Now())
+	tw.seq++
+	msg.seq = tw.seq
+	entry := &wheelEntry[T]{item: msg}
+	tw.m[msg.id()] = entry
+	if tw.place(entry) {
+		tw.signal()
+	}
+	return nil
+}
+
+// Peek 提取最近的一个任务详情，但并不出队
+//
+// 时间轮不维护全局顺序，未到期时需遍历全部任务，复杂度为O(n)
+func (tw *TimingWheelDelayQueue[T]) Peek() *MessageItem[T] {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+	if front := tw.ready.Front(); front != nil {
+		return front.Value.(*wheelEntry[T]).item
+	}
+	var earliest *MessageItem[T]
+	for _, entry := range tw.m {
+		if earliest == nil || entry.item.before(earliest) {
+			earliest = entry.item
+		}
+	}
+	return earliest
+}
+
+// Search 查询任务
+func (tw *TimingWheelDelayQueue[T]) Search(key string) (*MessageItem[T], error) {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+	if entry, ok := tw.m[key]; ok {
+		return entry.item, nil
+	}
+	return nil, nil
+}
+
+// Len 待触发的任务数量(含已到期未被消费的任务)
+func (tw *TimingWheelDelayQueue[T]) Len() int {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+	return len(tw.m)
+}
+
+// Delete 移除任务
+func (tw *TimingWheelDelayQueue[T]) Delete(key string) error {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+	entry, ok := tw.m[key]
+	if !ok {
+		return KeyError
+	}
+	entry.list.Remove(entry.elem)
+	delete(tw.m, key)
+	return nil
+}
+
+// Reschedule 修改任务的过期时间，复杂度O(1)
+func (tw *TimingWheelDelayQueue[T]) Reschedule(key string, sec time.Time) error {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+	entry, ok := tw.m[key]
+	if !ok {
+		return KeyError
+	}
+	entry.list.Remove(entry.elem)
+	entry.item.sec = sec
+	if tw.place(entry) {
+		tw.signal()
+	}
+	return nil
+}
+
+// UpdateContent 修改任务的消息内容，不影响触发时间
+func (tw *TimingWheelDelayQueue[T]) UpdateContent(key string, content T) error {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+	entry, ok := tw.m[key]
+	if !ok {
+		return KeyError
+	}
+	entry.item.content = content
+	return nil
+}
+
+// Upsert 添加任务，key已存在时原子地替换为新任务
+func (tw *TimingWheelDelayQueue[T]) Upsert(msg *MessageItem[T]) error {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+	if old, ok := tw.m[msg.id()]; ok {
+		old.list.Remove(old.elem)
+		delete(tw.m, msg.id())
+	}
+	return tw.add(msg)
+}
+
+// Watch 监听延迟队列，该方法会阻塞，直到有事件到期
+//
+// @param ctx 通过ctx.Done控制读取延迟的阻塞停止
+func (tw *TimingWheelDelayQueue[T]) Watch(ctx context.Context) (*MessageItem[T], error) {
+	for {
+		tw.lock.Lock()
+		if front := tw.ready.Front(); front != nil {
+			entry := tw.ready.Remove(front).(*wheelEntry[T])
+			delete(tw.m, entry.item.id())
+			if next := entry.item.next(tw.clock.Now()); next != nil { // 周期消息加入下一次触发
+				_ = tw.add(next)
+			}
+			if tw.ready.Len() > 0 { // 还有到期消息，唤醒其他等待者
+				tw.signal()
+			}
+			tw.lock.Unlock()
+			return entry.item, nil
+		}
+		tw.lock.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, CtxDoneError
+		case <-tw.done:
+			return nil, ClosedError
+		case <-tw.notify:
+		}
+	}
+}
+
+// Close 停止时间轮推进，阻塞中的Watch将返回ClosedError
+func (tw *TimingWheelDelayQueue[T]) Close() {
+	tw.closeOnce.Do(func() {
+		close(tw.done)
+	})
+}
+
+// run 在每个整秒推进一次时间轮，使消息在过期时间所在秒结束时立即触发
+func (tw *TimingWheelDelayQueue[T]) run() {
+	timer := tw.clock.NewTimer(untilNextSecond(tw.clock.Now()))
+	defer timer.Stop()
+	for {
+		select {
+		case <-tw.done:
+			return
+		case now := <-timer.C():
+			tw.advance(now.Unix())
+			timer.Reset(untilNextSecond(tw.clock.Now()))
+		}
+	}
+}
+
+// untilNextSecond 距离下一个整秒的时长
+func untilNextSecond(now time.Time) time.Duration {
+	return now.Truncate(time.Second).Add(time.Second).Sub(now)
+}
+
+// advance 将时间轮推进到指定刻度，若进程调度延迟导致落后多个刻度，会逐个补齐
+func (tw *TimingWheelDelayQueue[T]) advance(to int64) {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+	before := tw.ready.Len()
+	for tw.cur < to {
+		tw.skip(to - 1)
+		tw.cur++
+		tw.tick()
+	}
+	if tw.ready.Len() > before {
+		tw.signal()
+	}
+}
+
+// skip 低层时间轮全部为空时，直接跳到上一层下次降级的前一个刻度(不超过limit)，避免长时间落后时逐秒空转
+func (tw *TimingWheelDelayQueue[T]) skip(limit int64) {
+	for i := 0; i < len(wheelLevels)-1 && tw.empty(i); i++ {
+		unit := wheelLevels[i+1].unit
+		next := min((tw.cur/unit+1)*unit-1, limit)
+		if next <= tw.cur {
+			return
+		}
+		tw.cur = next
+	}
+}
+
+// empty 第level层时间轮是否没有任何消息
+func (tw *TimingWheelDelayQueue[T]) empty(level int) bool {
+	for _, slot := range tw.wheels[level] {
+		if slot.Len() > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// tick 处理当前刻度：先由高到低将到点的上层槽位降级，再将秒轮当前槽位移入就绪队列
+func (tw *TimingWheelDelayQueue[T]) tick() {
+	for i := len(wheelLevels) - 1; i > 0; i-- {
+		lv := wheelLevels[i]
+		if tw.cur%lv.unit != 0 {
+			continue
+		}
+		slot := tw.cur / lv.unit % lv.slots
+		cascade := tw.wheels[i][slot]
+		tw.wheels[i][slot] = list.New()
+		for e := cascade.Front(); e != nil; e = e.Next() {
+			tw.place(e.Value.(*wheelEntry[T]))
+		}
+	}
+	slot := tw.cur % wheelLevels[0].slots
+	due := tw.wheels[0][slot]
+	tw.wheels[0][slot] = list.New()
+	for e := due.Front(); e != nil; e = e.Next() {
+		tw.pushReady(e.Value.(*wheelEntry[T]))
+	}
+}
+
+// pushReady 按触发顺序插入就绪队列(由队尾向前查找插入位置，按时间顺序到期时为O(1))
+func (tw *TimingWheelDelayQueue[T]) pushReady(entry *wheelEntry[T]) {
+	entry.list = tw.ready
+	for e := tw.ready.Back(); e != nil; e = e.Prev() {
+		if !entry.item.before(e.Value.(*wheelEntry[T]).item) {
+			entry.elem = tw.ready.InsertAfter(entry, e)
+			return
+		}
+	}
+	entry.elem = tw.ready.PushFront(entry)
+}
+
+// place 根据剩余刻度将消息放入对应层级的槽位，已到期的消息直接放入就绪队列(返回true)
+func (tw *TimingWheelDelayQueue[T]) place(entry *wheelEntry[T]) bool {
+	expire := expireTick(entry.item.sec)
+	delay := expire - tw.cur
+	if delay <= 0 {
+		tw.pushReady(entry)
+		return true
+	}
+	for i, lv := range wheelLevels {
+		span := lv.unit * lv.slots
+		if delay >= span && i < len(wheelLevels)-1 {
+			continue
+		}
+		slot := expire / lv.unit % lv.slots
+		if delay >= span { // 超出最大跨度，先挂在最远的槽位上，降级时会重新计算位置
+			slot = (tw.cur/lv.unit + lv.slots - 1) % lv.slots
+		}
+		entry.list = tw.wheels[i][slot]
+		entry.elem = entry.list.PushBack(entry)
+		break
+	}
+	return false
+}
+
+// signal 非阻塞地通知等待中的Watch
+func (tw *TimingWheelDelayQueue[T]) signal() {
+	select {
+	case tw.notify <- struct{}{}:
+	default:
+	}
+}
+
+// expireTick 计算消息的触发刻度(向上取整，保证不会提前触发)
+func expireTick(sec time.Time) int64 {
+	tick := sec.Unix()
+	if sec.Nanosecond() > 0 {
+		tick++
+	}
+	return tick
+}
@@ -0,0 +1,74 @@
+package delayQueue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// 手动推进刻度，校验各层级消息都在正确的刻度触发
+func Test_TimingWheelCascade(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 30, 0, time.Local)
+	tw := newTimingWheelDelayQe[string](start.Unix())
+	delays := []time.Duration{
+		3 * time.Second,
+		90 * time.Second,
+		2*time.Hour + 5*time.Second,
+		3*24*time.Hour + time.Minute,
+		400 * 24 * time.Hour, // 超出天轮跨度
+	}
+	for i, d := range delays {
+		if err := tw.Add(NewMessageItem(string(rune('a'+i)), "", start.Add(d))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, d := range delays {
+		expire := start.Add(d).Unix()
+		tw.advance(expire - 1)
+		if tw.ready.Len() != 0 {
+			t.Fatalf("item %d fired too early", i)
+		}
+		tw.advance(expire)
+		msg, err := tw.Watch(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.key != string(rune('a'+i)) {
+			t.Fatalf("expect %c got %s", 'a'+i, msg.key)
+		}
+	}
+	if len(tw.m) != 0 {
+		t.Fatalf("expect empty wheel, got %d", len(tw.m))
+	}
+}
+
+func Test_TimingWheelDelete(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 30, 0, time.Local)
+	tw := newTimingWheelDelayQe[int](start.Unix())
+	_ = tw.Add(NewMessageItem("k1", 1, start.Add(5*time.Second)))
+	_ = tw.Add(NewMessageItem("k2", 2, start.Add(10*time.Minute)))
+	if err := tw.Add(NewMessageItem("k1", 3, start.Add(time.Second))); !errors.Is(err, RepeatError) {
+		t.Fatalf("expect RepeatError got %v", err)
+	}
+	if err := tw.Delete("k1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Delete("k1"); !errors.Is(err, KeyError) {
+		t.Fatalf("expect KeyError got %v", err)
+	}
+	if msg := tw.Peek(); msg == nil || msg.key != "k2" {
+		t.Fatalf("expect peek k2 got %v", msg)
+	}
+	tw.advance(expireTick(start.Add(10 * time.Minute)))
+	ctx, cancel := context.WithCancel(context.Background())
+	msg, err := tw.Watch(ctx)
+	if err != nil || msg.Content() != 2 {
+		t.Fatalf("expect k2 got %v %v", msg, err)
+	}
+	// 没有就绪的消息时，已取消的ctx立即返回
+	cancel()
+	if _, err = tw.Watch(ctx); !errors.Is(err, CtxDoneError) {
+		t.Fatalf("expect CtxDoneError got %v", err)
+	}
+}
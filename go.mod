@@ -1,6 +1,6 @@
 module git.woa.com/kf_cdms/go-public
 
-go 1.21.4
+go 1.23
 
 require (
 	github.com/ghodss/yaml v1.0.0
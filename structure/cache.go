@@ -0,0 +1,355 @@
+package structure
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// EvictionPolicy 缓存淘汰策略
+type EvictionPolicy int
+
+const (
+	LRU EvictionPolicy = iota // 淘汰最久未访问的元素(默认)
+	LFU                       // 淘汰访问次数最少的元素，次数相同时淘汰最久未访问的
+)
+
+// EvictReason 元素被移出缓存的原因
+type EvictReason int
+
+const (
+	EvictCapacity EvictReason = iota // 超出容量被淘汰
+	EvictExpired                     // 过期
+)
+
+// CacheOption 缓存配置项
+type CacheOption[K comparable, V any] func(c *Cache[K, V])
+
+// WithPolicy 淘汰策略，默认LRU
+func WithPolicy[K comparable, V any](policy EvictionPolicy) CacheOption[K, V] {
+	return func(c *Cache[K, V]) {
+		c.policy = policy
+	}
+}
+
+// WithMaxEntries 最大元素数量，<=0表示不限
+func WithMaxEntries[K comparable, V any](n int) CacheOption[K, V] {
+	return func(c *Cache[K, V]) {
+		c.maxEntries = n
+	}
+}
+
+// WithMaxCost 按成本限制容量，所有元素的成本之和不超过maxCost
+//
+// @param cost 计算元素成本(如字节数)的函数
+func WithMaxCost[K comparable, V any](maxCost int64, cost func(key K, val V) int64) CacheOption[K, V] {
+	return func(c *Cache[K, V]) {
+		c.maxCost = maxCost
+		c.cost = cost
+	}
+}
+
+// WithTTL 默认过期时间，<=0表示不过期，可通过 SetWithTTL 单独指定
+func WithTTL[K comparable, V any](ttl time.Duration) CacheOption[K, V] {
+	return func(c *Cache[K, V]) {
+		c.ttl = ttl
+	}
+}
+
+// WithOnEvict 元素被淘汰或过期时的回调，在释放锁后执行，可以安全地调用缓存的方法
+func WithOnEvict[K comparable, V any](onEvict func(key K, val V, reason EvictReason)) CacheOption[K, V] {
+	return func(c *Cache[K, V]) {
+		c.onEvict = onEvict
+	}
+}
+
+// CacheStats 缓存统计信息
+type CacheStats struct {
+	Len         int    // 元素数量(含已过期但尚未清理的元素)
+	Cost        int64  // 成本之和
+	Hits        uint64 // 命中次数
+	Misses      uint64 // 未命中次数(含已过期)
+	Evictions   uint64 // 超出容量淘汰的数量
+	Expirations uint64 // 过期清理的数量
+}
+
+// HitRate 命中率
+func (s CacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// cacheEntry 缓存元素
+type cacheEntry[K comparable, V any] struct {
+	key    K
+	val    V
+	cost   int64
+	expire time.Time     // 过期时间，零值表示不过期
+	freq   int           // 访问次数(LFU)
+	tick   uint64        // 最近访问的序号(LFU)
+	elem   *list.Element // 在访问链表中的位置(LRU)
+}
+
+// evicted 待回调的淘汰元素
+type evicted[K comparable, V any] struct {
+	entry  *cacheEntry[K, V]
+	reason EvictReason
+}
+
+// Cache 支持LRU/LFU淘汰与过期时间的缓存，并发安全
+//
+// LRU基于双向链表，LFU基于索引堆(IndexedHeap)，读写复杂度分别为O(1)与O(log2n)。
+// 过期元素在访问时惰性清理，也可调用 PurgeExpired 主动清理
+type Cache[K comparable, V any] struct {
+	m          map[K]*cacheEntry[K, V]
+	lru        *list.List                             // 访问链表，队首为最近访问
+	lfu        *IndexedHeap[K, *cacheEntry[K, V]]     // 按访问次数排序的小顶堆
+	tick       uint64                                 // 访问序号
+	policy     EvictionPolicy                         // 淘汰策略
+	maxEntries int                                    // 最大元素数量
+	maxCost    int64                                  // 最大成本
+	cost       func(key K, val V) int64               // 成本函数
+	totalCost  int64                                  // 当前成本之和
+	ttl        time.Duration                          // 默认过期时间
+	onEvict    func(key K, val V, reason EvictReason) // 淘汰回调
+	now        func() time.Time                       // 当前时间
+	stats      CacheStats
+	lock       sync.Mutex
+}
+
+// NewCache 初始化缓存
+func NewCache[K comparable, V any](opts ...CacheOption[K, V]) *Cache[K, V] {
+	c := &Cache[K, V]{
+		m:   make(map[K]*cacheEntry[K, V]),
+		now: time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.policy == LFU {
+		c.lfu = NewIndexedHeap[K, *cacheEntry[K, V]](func(a, b *cacheEntry[K, V]) bool {
+			if a.freq != b.freq {
+				return a.freq < b.freq
+			}
+			return a.tick < b.tick
+		})
+	} else {
+		c.lru = list.New()
+	}
+	return c
+}
+
+// Get 读取缓存，不存在或已过期时ok为false
+func (c *Cache[K, V]) Get(key K) (val V, ok bool) {
+	var victims []evicted[K, V]
+	c.lock.Lock()
+	e, ok := c.m[key]
+	switch {
+	case !ok:
+		c.stats.Misses++
+	case c.expired(e):
+		c.remove(e)
+		c.stats.Expirations++
+		c.stats.Misses++
+		victims = append(victims, evicted[K, V]{e, EvictExpired})
+		ok = false
+	default:
+		c.stats.Hits++
+		c.touch(e)
+		val = e.val
+	}
+	c.lock.Unlock()
+	c.notify(victims)
+	return val, ok
+}
+
+// Peek 读取缓存，不更新访问记录与命中统计
+func (c *Cache[K, V]) Peek(key K) (val V, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if e, ok := c.m[key]; ok && !c.expired(e) {
+		return e.val, true
+	}
+	return val, false
+}
+
+// Set 写入缓存，使用默认过期时间。单个元素的成本超过最大成本时不写入并返回false
+func (c *Cache[K, V]) Set(key K, val V) bool {
+	return c.SetWithTTL(key, val, c.ttl)
+}
+
+// SetWithTTL 写入缓存并指定过期时间，ttl<=0表示不过期
+func (c *Cache[K, V]) SetWithTTL(key K, val V, ttl time.Duration) bool {
+	cost := int64(1)
+	if c.cost != nil {
+		cost = c.cost(key, val)
+	}
+	c.lock.Lock()
+	if c.maxCost > 0 && cost > c.maxCost {
+		c.lock.Unlock()
+		return false
+	}
+	e, ok := c.m[key]
+	if ok {
+		c.totalCost -= e.cost
+		e.val, e.cost = val, cost
+		c.touch(e)
+	} else {
+		e = &cacheEntry[K, V]{key: key, val: val, cost: cost}
+		c.m[key] = e
+		if c.lfu != nil {
+			c.tick++
+			e.tick = c.tick
+			c.lfu.Push(key, e)
+		} else {
+			e.elem = c.lru.PushFront(e)
+		}
+	}
+	c.totalCost += cost
+	e.expire = time.Time{}
+	if ttl > 0 {
+		e.expire = c.now().Add(ttl)
+	}
+	victims := c.evict(e)
+	c.lock.Unlock()
+	c.notify(victims)
+	return true
+}
+
+// Delete 删除缓存，不触发淘汰回调
+func (c *Cache[K, V]) Delete(key K) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	e, ok := c.m[key]
+	if ok {
+		c.remove(e)
+	}
+	return ok
+}
+
+// Len 元素数量(含已过期但尚未清理的元素)
+func (c *Cache[K, V]) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.m)
+}
+
+// Clear 清空缓存，不触发淘汰回调，统计信息保留
+func (c *Cache[K, V]) Clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, e := range c.m {
+		c.remove(e)
+	}
+}
+
+// PurgeExpired 清理全部已过期的元素，返回清理的数量
+func (c *Cache[K, V]) PurgeExpired() int {
+	c.lock.Lock()
+	var victims []evicted[K, V]
+	for _, e := range c.m {
+		if c.expired(e) {
+			c.remove(e)
+			c.stats.Expirations++
+			victims = append(victims, evicted[K, V]{e, EvictExpired})
+		}
+	}
+	c.lock.Unlock()
+	c.notify(victims)
+	return len(victims)
+}
+
+// Stats 统计信息
+func (c *Cache[K, V]) Stats() CacheStats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	st := c.stats
+	st.Len = len(c.m)
+	st.Cost = c.totalCost
+	return st
+}
+
+// touch 记录一次访问
+func (c *Cache[K, V]) touch(e *cacheEntry[K, V]) {
+	if c.lfu != nil {
+		c.tick++
+		e.freq++
+		e.tick = c.tick
+		c.lfu.Update(e.key, e)
+		return
+	}
+	c.lru.MoveToFront(e.elem)
+}
+
+// remove 移除元素
+func (c *Cache[K, V]) remove(e *cacheEntry[K, V]) {
+	delete(c.m, e.key)
+	c.totalCost -= e.cost
+	if c.lfu != nil {
+		c.lfu.Remove(e.key)
+		return
+	}
+	c.lru.Remove(e.elem)
+}
+
+// evict 超出容量时按策略淘汰元素，刚写入的元素keep不会被淘汰
+func (c *Cache[K, V]) evict(keep *cacheEntry[K, V]) []evicted[K, V] {
+	var victims []evicted[K, V]
+	for (c.maxEntries > 0 && len(c.m) > c.maxEntries) || (c.maxCost > 0 && c.totalCost > c.maxCost) {
+		victim := c.victim(keep)
+		if victim == nil {
+			break
+		}
+		c.remove(victim)
+		reason := EvictCapacity
+		if c.expired(victim) {
+			reason = EvictExpired
+			c.stats.Expirations++
+		} else {
+			c.stats.Evictions++
+		}
+		victims = append(victims, evicted[K, V]{victim, reason})
+	}
+	return victims
+}
+
+// victim 按策略选出待淘汰的元素
+func (c *Cache[K, V]) victim(keep *cacheEntry[K, V]) *cacheEntry[K, V] {
+	if c.lfu != nil {
+		_, e, ok := c.lfu.Peek()
+		if !ok {
+			return nil
+		}
+		if e == keep { // 新写入的元素访问次数最少，临时移出后取下一个
+			c.lfu.Remove(keep.key)
+			_, e, ok = c.lfu.Peek()
+			c.lfu.Push(keep.key, keep)
+			if !ok {
+				return nil
+			}
+		}
+		return e
+	}
+	for back := c.lru.Back(); back != nil; back = back.Prev() {
+		if e := back.Value.(*cacheEntry[K, V]); e != keep {
+			return e
+		}
+	}
+	return nil
+}
+
+func (c *Cache[K, V]) expired(e *cacheEntry[K, V]) bool {
+	return !e.expire.IsZero() && !c.now().Before(e.expire)
+}
+
+// notify 在锁外执行淘汰回调
+func (c *Cache[K, V]) notify(victims []evicted[K, V]) {
+	if c.onEvict == nil {
+		return
+	}
+	for _, v := range victims {
+		c.onEvict(v.entry.key, v.entry.val, v.reason)
+	}
+}
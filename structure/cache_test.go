@@ -0,0 +1,118 @@
+package structure
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_CacheLRU(t *testing.T) {
+	var evicted []int
+	c := NewCache[int, string](WithMaxEntries[int, string](2), WithOnEvict(func(key int, val string, reason EvictReason) {
+		if reason == EvictCapacity {
+			evicted = append(evicted, key)
+		}
+	}))
+	c.Set(1, "a")
+	c.Set(2, "b")
+	c.Get(1) // 2成为最久未访问
+	c.Set(3, "c")
+	if _, ok := c.Get(2); ok || len(evicted) != 1 || evicted[0] != 2 {
+		t.Fatalf("expect 2 evicted got %v", evicted)
+	}
+	if v, ok := c.Get(1); !ok || v != "a" {
+		t.Fatalf("expect a got %s", v)
+	}
+	if st := c.Stats(); st.Hits != 2 || st.Misses != 1 || st.Evictions != 1 || st.Len != 2 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+}
+
+func Test_CacheLFU(t *testing.T) {
+	c := NewCache[string, int](WithPolicy[string, int](LFU), WithMaxEntries[string, int](3))
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+	for i := 0; i < 3; i++ {
+		c.Get("a")
+		c.Get("c")
+	}
+	c.Get("b")
+	c.Get("c")
+	c.Set("d", 4) // b访问次数最少
+	if _, ok := c.Peek("b"); ok {
+		t.Fatal("expect b evicted")
+	}
+	c.Set("e", 5) // 新写入的d访问次数最少
+	if _, ok := c.Peek("d"); ok || c.Len() != 3 {
+		t.Fatal("expect d evicted")
+	}
+	for _, key := range []string{"a", "c", "e"} {
+		if _, ok := c.Peek(key); !ok {
+			t.Fatalf("expect %s kept", key)
+		}
+	}
+}
+
+func Test_CacheTTLAndCost(t *testing.T) {
+	now := time.Now()
+	var expired []string
+	c := NewCache[string, string](
+		WithTTL[string, string](time.Minute),
+		WithMaxCost(10, func(key, val string) int64 { return int64(len(val)) }),
+		WithOnEvict(func(key, val string, reason EvictReason) {
+			if reason == EvictExpired {
+				expired = append(expired, key)
+			}
+		}))
+	c.now = func() time.Time { return now }
+	c.Set("a", "1234")
+	c.SetWithTTL("b", "1234", time.Hour)
+	if c.Set("big", "12345678901") {
+		t.Fatal("expect oversized value rejected")
+	}
+	c.Set("c", "1234") // 成本12超过10，淘汰最久未访问的a
+	if _, ok := c.Get("a"); ok || c.Stats().Cost != 8 {
+		t.Fatalf("expect a evicted, cost %d", c.Stats().Cost)
+	}
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.Get("c"); ok {
+		t.Fatal("expect c expired")
+	}
+	if n := c.PurgeExpired(); n != 0 || len(expired) != 1 || expired[0] != "c" {
+		t.Fatalf("unexpected expired %v", expired)
+	}
+	if v, ok := c.Get("b"); !ok || v != "1234" {
+		t.Fatal("expect b alive")
+	}
+	now = now.Add(time.Hour)
+	if n := c.PurgeExpired(); n != 1 || c.Len() != 0 || c.Stats().Expirations != 2 {
+		t.Fatalf("expect b purged got %d", n)
+	}
+}
+
+func Test_CacheConcurrent(t *testing.T) {
+	for _, policy := range []EvictionPolicy{LRU, LFU} {
+		c := NewCache[int, int](WithPolicy[int, int](policy), WithMaxEntries[int, int](50))
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					key := (g*31 + i) % 100
+					if _, ok := c.Get(key); !ok {
+						c.Set(key, i)
+					}
+					if i%17 == 0 {
+						c.Delete(key)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		if st := c.Stats(); st.Len > 50 || st.Hits+st.Misses != 8000 {
+			t.Fatalf("unexpected stats %+v", st)
+		}
+	}
+}
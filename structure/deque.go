@@ -0,0 +1,213 @@
+package structure
+
+import "iter"
+
+// dequeMinCap 双端队列的初始容量，容量始终为2的幂，便于用位运算取模
+const dequeMinCap = 8
+
+// Deque 双端队列，基于可扩容的环形数组实现，两端入队、出队均摊O(1)。非并发安全类型
+type Deque[T any] struct {
+	buf  []T
+	head int // 队首下标
+	size int // 元素数量
+}
+
+// NewDeque 初始化双端队列
+//
+// @param capacity 预分配容量，不足时自动扩容
+func NewDeque[T any](capacity int) *Deque[T] {
+	c := dequeMinCap
+	for c < capacity {
+		c <<= 1
+	}
+	return &Deque[T]{buf: make([]T, c)}
+}
+
+// PushBack 队尾入队
+func (d *Deque[T]) PushBack(v T) {
+	d.grow()
+	d.buf[(d.head+d.size)&(len(d.buf)-1)] = v
+	d.size++
+}
+
+// PushFront 队首入队
+func (d *Deque[T]) PushFront(v T) {
+	d.grow()
+	d.head = (d.head - 1) & (len(d.buf) - 1)
+	d.buf[d.head] = v
+	d.size++
+}
+
+// PopFront 队首出队，队列为空时ok为false
+func (d *Deque[T]) PopFront() (v T, ok bool) {
+	if d.size == 0 {
+		return v, false
+	}
+	var zero T
+	v, d.buf[d.head] = d.buf[d.head], zero
+	d.head = (d.head + 1) & (len(d.buf) - 1)
+	d.size--
+	return v, true
+}
+
+// PopBack 队尾出队，队列为空时ok为false
+func (d *Deque[T]) PopBack() (v T, ok bool) {
+	if d.size == 0 {
+		return v, false
+	}
+	var zero T
+	idx := (d.head + d.size - 1) & (len(d.buf) - 1)
+	v, d.buf[idx] = d.buf[idx], zero
+	d.size--
+	return v, true
+}
+
+// PeekFront 返回队首元素但不出队，队列为空时ok为false
+func (d *Deque[T]) PeekFront() (v T, ok bool) {
+	if d.size == 0 {
+		return v, false
+	}
+	return d.buf[d.head], true
+}
+
+// PeekBack 返回队尾元素但不出队，队列为空时ok为false
+func (d *Deque[T]) PeekBack() (v T, ok bool) {
+	if d.size == 0 {
+		return v, false
+	}
+	return d.buf[(d.head+d.size-1)&(len(d.buf)-1)], true
+}
+
+// Get 返回第i个元素(队首为0)，越界时ok为false
+func (d *Deque[T]) Get(i int) (v T, ok bool) {
+	if i < 0 || i >= d.size {
+		return v, false
+	}
+	return d.buf[(d.head+i)&(len(d.buf)-1)], true
+}
+
+// Len 元素数量
+func (d *Deque[T]) Len() int {
+	return d.size
+}
+
+// IsEmpty 队列是否为空
+func (d *Deque[T]) IsEmpty() bool {
+	return d.size == 0
+}
+
+// Clear 清空队列，保留已分配的容量
+func (d *Deque[T]) Clear() {
+	clear(d.buf)
+	d.head, d.size = 0, 0
+}
+
+// Clone 拷贝队列(浅拷贝元素)
+func (d *Deque[T]) Clone() *Deque[T] {
+	cp := &Deque[T]{buf: make([]T, len(d.buf)), size: d.size}
+	d.copyTo(cp.buf)
+	return cp
+}
+
+// All 由队首到队尾遍历全部元素，不修改队列
+func (d *Deque[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := 0; i < d.size; i++ {
+			if !yield(d.buf[(d.head+i)&(len(d.buf)-1)]) {
+				return
+			}
+		}
+	}
+}
+
+// Drain 由队首到队尾依次出队，遍历中途停止时未出队的元素保留在队列中
+func (d *Deque[T]) Drain() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for d.size > 0 {
+			v, _ := d.PopFront()
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// grow 容量已满时扩容为两倍，并将元素按顺序搬到数组开头
+func (d *Deque[T]) grow() {
+	if d.buf == nil {
+		d.buf = make([]T, dequeMinCap)
+	}
+	if d.size < len(d.buf) {
+		return
+	}
+	buf := make([]T, len(d.buf)<<1)
+	d.copyTo(buf)
+	d.buf, d.head = buf, 0
+}
+
+// copyTo 将元素按队首到队尾的顺序复制到dst开头
+func (d *Deque[T]) copyTo(dst []T) {
+	if d.head+d.size <= len(d.buf) {
+		copy(dst, d.buf[d.head:d.head+d.size])
+		return
+	}
+	n := copy(dst, d.buf[d.head:])
+	copy(dst[n:], d.buf[:d.size-n])
+}
+
+// Queue 先进先出队列，基于环形数组实现，入队、出队均摊O(1)。非并发安全类型
+type Queue[T any] struct {
+	d Deque[T]
+}
+
+// NewQueue 初始化队列
+//
+// @param capacity 预分配容量，不足时自动扩容
+func NewQueue[T any](capacity int) *Queue[T] {
+	return &Queue[T]{d: *NewDeque[T](capacity)}
+}
+
+// Push 入队
+func (q *Queue[T]) Push(v T) {
+	q.d.PushBack(v)
+}
+
+// Pop 出队，队列为空时ok为false
+func (q *Queue[T]) Pop() (v T, ok bool) {
+	return q.d.PopFront()
+}
+
+// Peek 返回队首元素但不出队，队列为空时ok为false
+func (q *Queue[T]) Peek() (v T, ok bool) {
+	return q.d.PeekFront()
+}
+
+// Len 元素数量
+func (q *Queue[T]) Len() int {
+	return q.d.Len()
+}
+
+// IsEmpty 队列是否为空
+func (q *Queue[T]) IsEmpty() bool {
+	return q.d.IsEmpty()
+}
+
+// Clear 清空队列，保留已分配的容量
+func (q *Queue[T]) Clear() {
+	q.d.Clear()
+}
+
+// Clone 拷贝队列(浅拷贝元素)
+func (q *Queue[T]) Clone() *Queue[T] {
+	return &Queue[T]{d: *q.d.Clone()}
+}
+
+// All 按出队顺序遍历全部元素，不修改队列
+func (q *Queue[T]) All() iter.Seq[T] {
+	return q.d.All()
+}
+
+// Drain 依次出队，遍历中途停止时未出队的元素保留在队列中
+func (q *Queue[T]) Drain() iter.Seq[T] {
+	return q.d.Drain()
+}
@@ -0,0 +1,117 @@
+package structure
+
+import (
+	"slices"
+	"testing"
+)
+
+func Test_Deque(t *testing.T) {
+	d := NewDeque[int](0)
+	// 两端交替写入，触发多次扩容且数据跨越数组首尾
+	expect := make([]int, 0)
+	for i := 0; i < 100; i++ {
+		if i%2 == 0 {
+			d.PushBack(i)
+			expect = append(expect, i)
+		} else {
+			d.PushFront(i)
+			expect = append([]int{i}, expect...)
+		}
+	}
+	if got := slices.Collect(d.All()); !slices.Equal(got, expect) || d.Len() != 100 {
+		t.Fatalf("unexpected deque %v", got)
+	}
+	if v, ok := d.Get(1); !ok || v != expect[1] {
+		t.Fatalf("expect %d got %d", expect[1], v)
+	}
+	cp := d.Clone()
+	for len(expect) > 0 {
+		front, _ := d.PeekFront()
+		back, _ := d.PeekBack()
+		if front != expect[0] || back != expect[len(expect)-1] {
+			t.Fatalf("unexpected front %d back %d", front, back)
+		}
+		v, _ := d.PopBack()
+		if v != back {
+			t.Fatalf("expect %d got %d", back, v)
+		}
+		expect = expect[:len(expect)-1]
+		if len(expect) > 0 {
+			v, _ = d.PopFront()
+			expect = expect[1:]
+			if v != front {
+				t.Fatalf("expect %d got %d", front, v)
+			}
+		}
+	}
+	if _, ok := d.PopFront(); ok || !d.IsEmpty() || cp.Len() != 100 {
+		t.Fatal("expect empty deque and untouched clone")
+	}
+	cp.Clear()
+	if _, ok := cp.PeekBack(); ok {
+		t.Fatal("expect cleared clone")
+	}
+}
+
+func Test_Queue(t *testing.T) {
+	var q = NewQueue[string](2)
+	for _, v := range []string{"a", "b", "c"} {
+		q.Push(v)
+	}
+	if v, ok := q.Peek(); !ok || v != "a" {
+		t.Fatalf("expect a got %s", v)
+	}
+	for v := range q.Drain() {
+		if v == "b" {
+			break
+		}
+	}
+	if v, ok := q.Pop(); !ok || v != "c" || !q.IsEmpty() {
+		t.Fatalf("expect c got %s", v)
+	}
+}
+
+func Test_QueueZeroValue(t *testing.T) {
+	var q Queue[int]
+	if _, ok := q.Pop(); ok || q.Len() != 0 {
+		t.Fatal("expect empty queue")
+	}
+	for i := 0; i < 20; i++ {
+		q.Push(i)
+	}
+	if c := q.Clone(); c.Len() != 20 {
+		t.Fatalf("expect clone len 20 got %d", c.Len())
+	}
+	for i := 0; i < 20; i++ {
+		if v, ok := q.Pop(); !ok || v != i {
+			t.Fatalf("expect %d got %d", i, v)
+		}
+	}
+}
+
+func Test_RingBuffer(t *testing.T) {
+	r := NewRingBuffer[int](3, OverwriteOldest)
+	for i := 1; i <= 5; i++ {
+		if !r.Push(i) {
+			t.Fatalf("expect overwrite push %d", i)
+		}
+	}
+	if got := slices.Collect(r.All()); !slices.Equal(got, []int{3, 4, 5}) || !r.IsFull() {
+		t.Fatalf("unexpected ring %v", got)
+	}
+	if v, ok := r.Pop(); !ok || v != 3 {
+		t.Fatalf("expect 3 got %d", v)
+	}
+
+	reject := NewRingBuffer[int](2, RejectNewest)
+	if !reject.Push(1) || !reject.Push(2) || reject.Push(3) {
+		t.Fatal("expect third push rejected")
+	}
+	if got := slices.Collect(reject.Clone().Drain()); !slices.Equal(got, []int{1, 2}) || reject.Len() != 2 {
+		t.Fatalf("unexpected drain %v", got)
+	}
+	reject.Clear()
+	if _, ok := reject.Peek(); ok || reject.Cap() != 2 {
+		t.Fatal("expect empty ring")
+	}
+}
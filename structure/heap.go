@@ -1,10 +1,13 @@
 package structure
 
+import "iter"
+
 // HeapArea 堆。非并发安全类型，并发场景下需自己加锁二次封装
 type HeapArea[T comparable] struct {
-	data []T
-	t    bool                          // true为大顶堆，false为小顶堆
-	less func(data []T, i, j int) bool // 比较两个元素谁更小，当i <j时返回true,否则返回false
+	data  []T
+	t     bool                          // true为大顶堆，false为小顶堆
+	less  func(data []T, i, j int) bool // 比较两个元素谁更小，当i <j时返回true,否则返回false
+	index func(val T, idx int)          // 元素位置变化时的回调，元素移出堆时idx为-1
 }
 
 // NewHeapArea 初始化堆数据结构，默认最小堆
@@ -16,11 +19,25 @@ func NewHeapArea[T comparable](t bool, less func(data []T, i, j int) bool) *Heap
 	}
 }
 
+// WithIndex 设置元素位置变化的回调，调用方可据此记录元素在堆中的下标，从而以O(log2n)完成 Fix/Delete，无需 Search 遍历
+func (h *HeapArea[T]) WithIndex(index func(val T, idx int)) *HeapArea[T] {
+	h.index = index
+	for i, v := range h.data {
+		index(v, i)
+	}
+	return h
+}
+
 // CreateFromSlice 将数组初始化为堆(自顶向下建堆，复杂度为O(log2n))
 func (h *HeapArea[T]) CreateFromSlice(d []T, t bool, less func(data []T, i, j int) bool) *HeapArea[T] {
 	h.data = d
 	h.t = t
 	h.less = less
+	if h.index != nil {
+		for i, v := range h.data {
+			h.index(v, i)
+		}
+	}
 	for i := fatherChild(len(h.data) - 1); i >= 0; i-- {
 		h.siftDown(i)
 	}
@@ -30,6 +47,9 @@ func (h *HeapArea[T]) CreateFromSlice(d []T, t bool, less func(data []T, i, j in
 // Push 新增。返回新增元素的索引下标
 func (h *HeapArea[T]) Push(val T) int {
 	h.data = append(h.data, val)
+	if h.index != nil {
+		h.index(val, len(h.data)-1)
+	}
 	// 从底至顶堆化
 	return h.siftUp(len(h.data) - 1)
 }
@@ -39,22 +59,77 @@ func (h *HeapArea[T]) Get(idx int) T {
 	return h.data[idx]
 }
 
+// Len 堆中元素数量
+func (h *HeapArea[T]) Len() int {
+	return len(h.data)
+}
+
+// IsEmpty 堆是否为空
+func (h *HeapArea[T]) IsEmpty() bool {
+	return len(h.data) == 0
+}
+
+// Clear 清空堆
+func (h *HeapArea[T]) Clear() {
+	if h.index != nil {
+		for _, v := range h.data {
+			h.index(v, -1)
+		}
+	}
+	h.data = make([]T, 0)
+}
+
+// Clone 拷贝堆(浅拷贝元素)。拷贝不会继承 WithIndex 的回调，避免修改拷贝时改写原堆元素记录的下标
+func (h *HeapArea[T]) Clone() *HeapArea[T] {
+	return &HeapArea[T]{
+		data: append(make([]T, 0, len(h.data)), h.data...),
+		t:    h.t,
+		less: h.less,
+	}
+}
+
+// All 按出堆顺序遍历全部元素，基于拷贝遍历，不修改堆，复杂度O(nlog2n)
+func (h *HeapArea[T]) All() iter.Seq[T] {
+	return h.Clone().Drain()
+}
+
+// Drain 按出堆顺序依次弹出元素，遍历中途停止时未弹出的元素保留在堆中
+func (h *HeapArea[T]) Drain() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for len(h.data) > 0 {
+			if !yield(h.Pop()) {
+				return
+			}
+		}
+	}
+}
+
+// Fix 索引处元素的值发生变化后，重新调整其在堆中的位置
+func (h *HeapArea[T]) Fix(idx int) {
+	if idx < 0 || idx >= len(h.data) {
+		return
+	}
+	if h.siftUp(idx) == idx {
+		h.siftDown(idx)
+	}
+}
+
 // Delete 根据索引删除堆中的元素
 func (h *HeapArea[T]) Delete(idx int) {
 	if idx >= len(h.data) {
 		return
 	}
-	// 交换堆顶和堆尾元素
+	// 交换待删除元素和堆尾元素
 	h.swap(idx, len(h.data)-1)
-	// 删除堆顶元素
-	h.data = h.data[:len(h.data)-1]
-	// 堆重新排序
-	h.siftDown(idx)
+	// 删除堆尾元素
+	h.removeLast()
+	// 堆重新排序(换上来的堆尾元素可能比父节点更小，需要上下都调整)
+	h.Fix(idx)
 }
 
 // 重新堆化 - 从底至顶堆化
 func (h *HeapArea[T]) siftUp(i int) int {
-	for {
+	for i > 0 {
 		// 获取节点的父节点
 		p := fatherChild(i)
 		// 越过根节点 或 节点大小比较不匹配，则结束
@@ -117,16 +192,28 @@ func (h *HeapArea[T]) siftDown(i int) {
 // 交换元素
 func (h *HeapArea[T]) swap(o, n int) {
 	h.data[o], h.data[n] = h.data[n], h.data[o]
+	if h.index != nil {
+		h.index(h.data[o], o)
+		h.index(h.data[n], n)
+	}
+}
+
+// 移除堆尾元素
+func (h *HeapArea[T]) removeLast() T {
+	v := h.data[len(h.data)-1]
+	h.data = h.data[:len(h.data)-1]
+	if h.index != nil {
+		h.index(v, -1)
+	}
+	return v
 }
 
 // Pop 弹出堆顶元素
 func (h *HeapArea[T]) Pop() T {
 	// 交换堆顶和堆尾元素
 	h.swap(0, len(h.data)-1)
-	// 获取堆顶元素
-	v := h.data[len(h.data)-1]
-	// 删除堆顶元素
-	h.data = h.data[:len(h.data)-1]
+	// 获取并删除堆顶元素
+	v := h.removeLast()
 	// 堆重新排序
 	h.siftDown(0)
 	// 返回
@@ -0,0 +1,59 @@
+package structure
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+type heapNode struct {
+	val   int
+	index int
+}
+
+func Test_HeapAreaIndex(t *testing.T) {
+	h := NewHeapArea[*heapNode](false, func(data []*heapNode, i, j int) bool {
+		return data[i].val < data[j].val
+	}).WithIndex(func(n *heapNode, idx int) {
+		n.index = idx
+	})
+	nodes := make([]*heapNode, 0)
+	for i := 0; i < 200; i++ {
+		n := &heapNode{val: rand.Intn(1000)}
+		nodes = append(nodes, n)
+		h.Push(n)
+	}
+	// 随机修改、删除，校验下标始终正确
+	for i := 0; i < 100; i++ {
+		n := nodes[i]
+		if i%2 == 0 {
+			n.val = rand.Intn(1000)
+			h.Fix(n.index)
+			continue
+		}
+		h.Delete(n.index)
+		if n.index != -1 {
+			t.Fatalf("expect removed index -1 got %d", n.index)
+		}
+	}
+	for i := 0; i < h.Len(); i++ {
+		if h.Get(i).index != i {
+			t.Fatalf("index mismatch at %d", i)
+		}
+	}
+	expect := make([]int, 0)
+	for i, n := range nodes {
+		if i >= 100 || i%2 == 0 {
+			expect = append(expect, n.val)
+		}
+	}
+	sort.Ints(expect)
+	for _, v := range expect {
+		if got := h.Pop().val; got != v {
+			t.Fatalf("expect %d got %d", v, got)
+		}
+	}
+	if h.Len() != 0 {
+		t.Fatalf("expect empty heap got %d", h.Len())
+	}
+}
@@ -0,0 +1,100 @@
+package structure
+
+// indexedEntry 索引堆中的元素
+type indexedEntry[K comparable, V any] struct {
+	key   K
+	val   V
+	index int
+}
+
+// IndexedHeap 索引堆(小顶堆)，维护 key -> 堆下标 的映射，按key修改、删除元素的复杂度均为O(log2n)。
+// 非并发安全类型，并发场景下需自己加锁二次封装
+type IndexedHeap[K comparable, V any] struct {
+	heap *HeapArea[*indexedEntry[K, V]]
+	m    map[K]*indexedEntry[K, V]
+}
+
+// NewIndexedHeap 初始化索引堆
+//
+// @param less a比b更优先(更靠近堆顶)时返回true，需要大顶堆时反转比较即可
+func NewIndexedHeap[K comparable, V any](less func(a, b V) bool) *IndexedHeap[K, V] {
+	return &IndexedHeap[K, V]{
+		heap: NewHeapArea[*indexedEntry[K, V]](false, func(data []*indexedEntry[K, V], i, j int) bool {
+			return less(data[i].val, data[j].val)
+		}).WithIndex(func(e *indexedEntry[K, V], idx int) {
+			e.index = idx
+		}),
+		m: make(map[K]*indexedEntry[K, V]),
+	}
+}
+
+// Push 新增元素，key已存在时等同于 Update
+func (h *IndexedHeap[K, V]) Push(key K, val V) {
+	if h.Update(key, val) {
+		return
+	}
+	e := &indexedEntry[K, V]{key: key, val: val}
+	h.m[key] = e
+	h.heap.Push(e)
+}
+
+// Pop 弹出堆顶元素，堆为空时ok为false
+func (h *IndexedHeap[K, V]) Pop() (key K, val V, ok bool) {
+	if h.heap.Len() == 0 {
+		return key, val, false
+	}
+	e := h.heap.Pop()
+	delete(h.m, e.key)
+	return e.key, e.val, true
+}
+
+// Peek 返回堆顶元素但不弹出，堆为空时ok为false
+func (h *IndexedHeap[K, V]) Peek() (key K, val V, ok bool) {
+	if h.heap.Len() == 0 {
+		return key, val, false
+	}
+	e := h.heap.Get(0)
+	return e.key, e.val, true
+}
+
+// Update 修改key对应的值并调整位置，key不存在时返回false
+func (h *IndexedHeap[K, V]) Update(key K, val V) bool {
+	e, ok := h.m[key]
+	if !ok {
+		return false
+	}
+	e.val = val
+	h.heap.Fix(e.index)
+	return true
+}
+
+// Remove 删除key对应的元素，key不存在时ok为false
+func (h *IndexedHeap[K, V]) Remove(key K) (val V, ok bool) {
+	e, ok := h.m[key]
+	if !ok {
+		return val, false
+	}
+	h.heap.Delete(e.index)
+	delete(h.m, key)
+	return e.val, true
+}
+
+// Get 查询key对应的值
+func (h *IndexedHeap[K, V]) Get(key K) (val V, ok bool) {
+	e, ok := h.m[key]
+	if !ok {
+		return val, false
+	}
+	return e.val, true
+}
+
+// Contains 是否包含key
+func (h *IndexedHeap[K, V]) Contains(key K) bool {
+	_, ok := h.m[key]
+	return ok
+}
+
+// Len 元素数量
+func (h *IndexedHeap[K, V]) Len() int {
+	return h.heap.Len()
+}
@@ -0,0 +1,59 @@
+package structure
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func Test_IndexedHeap(t *testing.T) {
+	h := NewIndexedHeap[int, int](func(a, b int) bool { return a < b })
+	vals := make(map[int]int)
+	for i := 0; i < 500; i++ {
+		vals[i] = rand.Intn(1000)
+		h.Push(i, vals[i])
+	}
+	// 随机修改、删除、重复Push
+	for i := 0; i < 300; i++ {
+		key := rand.Intn(500)
+		switch i % 3 {
+		case 0:
+			vals[key] = rand.Intn(1000)
+			if h.Contains(key) != h.Update(key, vals[key]) {
+				t.Fatalf("update %d mismatch contains", key)
+			}
+			if !h.Contains(key) {
+				delete(vals, key)
+			}
+		case 1:
+			v, ok := h.Remove(key)
+			if expect, exist := vals[key]; ok != exist || (ok && v != expect) {
+				t.Fatalf("remove %d: expect %d %v got %d %v", key, expect, exist, v, ok)
+			}
+			delete(vals, key)
+		default:
+			vals[key] = rand.Intn(1000)
+			h.Push(key, vals[key])
+		}
+	}
+	if h.Len() != len(vals) {
+		t.Fatalf("expect len %d got %d", len(vals), h.Len())
+	}
+	expect := make([]int, 0, len(vals))
+	for _, v := range vals {
+		expect = append(expect, v)
+	}
+	sort.Ints(expect)
+	if _, v, ok := h.Peek(); !ok || v != expect[0] {
+		t.Fatalf("expect peek %d got %d", expect[0], v)
+	}
+	for _, v := range expect {
+		key, got, ok := h.Pop()
+		if !ok || got != v || vals[key] != v || h.Contains(key) {
+			t.Fatalf("expect %d got %d(key %d)", v, got, key)
+		}
+	}
+	if _, _, ok := h.Pop(); ok || h.Len() != 0 {
+		t.Fatal("expect empty heap")
+	}
+}
@@ -0,0 +1,118 @@
+package structure
+
+import "iter"
+
+// RingPolicy 环形缓冲区写满时的处理策略
+type RingPolicy int
+
+const (
+	OverwriteOldest RingPolicy = iota // 覆盖最早写入的元素
+	RejectNewest                      // 拒绝写入
+)
+
+// RingBuffer 固定容量的环形缓冲区，不会扩容，写满后按策略覆盖或拒绝。非并发安全类型
+type RingBuffer[T any] struct {
+	buf    []T
+	head   int // 最早写入元素的下标
+	size   int // 元素数量
+	policy RingPolicy
+}
+
+// NewRingBuffer 初始化环形缓冲区，capacity<1时按1处理
+func NewRingBuffer[T any](capacity int, policy RingPolicy) *RingBuffer[T] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &RingBuffer[T]{buf: make([]T, capacity), policy: policy}
+}
+
+// Push 写入元素。缓冲区已满时，OverwriteOldest覆盖最早的元素并返回true，RejectNewest不写入并返回false
+func (r *RingBuffer[T]) Push(v T) bool {
+	if r.size == len(r.buf) {
+		if r.policy == RejectNewest {
+			return false
+		}
+		r.buf[r.head] = v
+		r.head = (r.head + 1) % len(r.buf)
+		return true
+	}
+	r.buf[(r.head+r.size)%len(r.buf)] = v
+	r.size++
+	return true
+}
+
+// Pop 取出最早写入的元素，缓冲区为空时ok为false
+func (r *RingBuffer[T]) Pop() (v T, ok bool) {
+	if r.size == 0 {
+		return v, false
+	}
+	var zero T
+	v, r.buf[r.head] = r.buf[r.head], zero
+	r.head = (r.head + 1) % len(r.buf)
+	r.size--
+	return v, true
+}
+
+// Peek 返回最早写入的元素但不取出，缓冲区为空时ok为false
+func (r *RingBuffer[T]) Peek() (v T, ok bool) {
+	if r.size == 0 {
+		return v, false
+	}
+	return r.buf[r.head], true
+}
+
+// Len 元素数量
+func (r *RingBuffer[T]) Len() int {
+	return r.size
+}
+
+// Cap 容量
+func (r *RingBuffer[T]) Cap() int {
+	return len(r.buf)
+}
+
+// IsEmpty 缓冲区是否为空
+func (r *RingBuffer[T]) IsEmpty() bool {
+	return r.size == 0
+}
+
+// IsFull 缓冲区是否已满
+func (r *RingBuffer[T]) IsFull() bool {
+	return r.size == len(r.buf)
+}
+
+// Clear 清空缓冲区
+func (r *RingBuffer[T]) Clear() {
+	clear(r.buf)
+	r.head, r.size = 0, 0
+}
+
+// Clone 拷贝缓冲区(浅拷贝元素)
+func (r *RingBuffer[T]) Clone() *RingBuffer[T] {
+	cp := *r
+	cp.buf = append([]T(nil), r.buf...)
+	return &cp
+}
+
+// All 由早到晚遍历全部元素，不修改缓冲区
+func (r *RingBuffer[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := 0; i < r.size; i++ {
+			if !yield(r.buf[(r.head+i)%len(r.buf)]) {
+				return
+			}
+		}
+	}
+}
+
+// Drain 由早到晚依次取出，遍历中途停止时未取出的元素保留在缓冲区中
+func (r *RingBuffer[T]) Drain() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for r.size > 0 {
+			v, _ := r.Pop()
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
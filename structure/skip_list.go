@@ -0,0 +1,281 @@
+package structure
+
+import (
+	"cmp"
+	"iter"
+	"math/rand/v2"
+)
+
+const (
+	skipListMaxLevel = 32 // 最大层数，足够容纳 4^32 个元素
+	skipListP        = 4  // 每个节点以 1/skipListP 的概率晋升一层
+)
+
+// skipLevel 节点在某一层的后继，span为跨越的节点数，用于排名查询
+type skipLevel[K cmp.Ordered, V any] struct {
+	next *skipNode[K, V]
+	span int
+}
+
+type skipNode[K cmp.Ordered, V any] struct {
+	key   K
+	val   V
+	prev  *skipNode[K, V] // 第0层的前驱，用于降序遍历，首个节点为nil
+	level []skipLevel[K, V]
+}
+
+// SkipList 跳表(有序映射)，按key升序存储，查找、插入、删除及排名查询的期望复杂度均为O(log2n)。非并发安全类型
+type SkipList[K cmp.Ordered, V any] struct {
+	head  *skipNode[K, V]
+	tail  *skipNode[K, V]
+	level int // 当前最高层数
+	size  int
+}
+
+// NewSkipList 初始化跳表
+func NewSkipList[K cmp.Ordered, V any]() *SkipList[K, V] {
+	return &SkipList[K, V]{
+		head:  &skipNode[K, V]{level: make([]skipLevel[K, V], skipListMaxLevel)},
+		level: 1,
+	}
+}
+
+// Len 元素数量
+func (s *SkipList[K, V]) Len() int {
+	return s.size
+}
+
+// Set 写入key，key已存在时覆盖并返回true
+func (s *SkipList[K, V]) Set(key K, val V) bool {
+	var (
+		update [skipListMaxLevel]*skipNode[K, V]
+		rank   [skipListMaxLevel]int // 各层update节点的排名(从1开始，head为0)
+	)
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		if i < s.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.level[i].next != nil && x.level[i].next.key < key {
+			rank[i] += x.level[i].span
+			x = x.level[i].next
+		}
+		update[i] = x
+	}
+	if next := x.level[0].next; next != nil && next.key == key {
+		next.val = val
+		return true
+	}
+	lvl := randomLevel()
+	if lvl > s.level {
+		for i := s.level; i < lvl; i++ {
+			update[i] = s.head
+			s.head.level[i].span = s.size
+		}
+		s.level = lvl
+	}
+	n := &skipNode[K, V]{key: key, val: val, level: make([]skipLevel[K, V], lvl)}
+	for i := 0; i < lvl; i++ {
+		n.level[i].next = update[i].level[i].next
+		update[i].level[i].next = n
+		n.level[i].span = update[i].level[i].span - (rank[0] - rank[i])
+		update[i].level[i].span = rank[0] - rank[i] + 1
+	}
+	for i := lvl; i < s.level; i++ {
+		update[i].level[i].span++
+	}
+	if update[0] != s.head {
+		n.prev = update[0]
+	}
+	if n.level[0].next != nil {
+		n.level[0].next.prev = n
+	} else {
+		s.tail = n
+	}
+	s.size++
+	return false
+}
+
+// Get 查询key对应的值
+func (s *SkipList[K, V]) Get(key K) (val V, ok bool) {
+	if n := s.ceiling(key); n != nil && n.key == key {
+		return n.val, true
+	}
+	return val, false
+}
+
+// Contains 是否包含key
+func (s *SkipList[K, V]) Contains(key K) bool {
+	_, ok := s.Get(key)
+	return ok
+}
+
+// Delete 删除key，返回被删除的值，key不存在时ok为false
+func (s *SkipList[K, V]) Delete(key K) (val V, ok bool) {
+	var update [skipListMaxLevel]*skipNode[K, V]
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.level[i].next != nil && x.level[i].next.key < key {
+			x = x.level[i].next
+		}
+		update[i] = x
+	}
+	x = x.level[0].next
+	if x == nil || x.key != key {
+		return val, false
+	}
+	for i := 0; i < s.level; i++ {
+		if update[i].level[i].next == x {
+			update[i].level[i].span += x.level[i].span - 1
+			update[i].level[i].next = x.level[i].next
+		} else {
+			update[i].level[i].span--
+		}
+	}
+	if x.level[0].next != nil {
+		x.level[0].next.prev = x.prev
+	} else {
+		s.tail = x.prev
+	}
+	for s.level > 1 && s.head.level[s.level-1].next == nil {
+		s.level--
+	}
+	s.size--
+	return x.val, true
+}
+
+// Min 最小的key
+func (s *SkipList[K, V]) Min() (key K, val V, ok bool) {
+	return entry(s.head.level[0].next)
+}
+
+// Max 最大的key
+func (s *SkipList[K, V]) Max() (key K, val V, ok bool) {
+	return entry(s.tail)
+}
+
+// Floor 小于等于key的最大元素
+func (s *SkipList[K, V]) Floor(key K) (K, V, bool) {
+	return entry(s.floor(key))
+}
+
+// Ceiling 大于等于key的最小元素
+func (s *SkipList[K, V]) Ceiling(key K) (K, V, bool) {
+	return entry(s.ceiling(key))
+}
+
+// Rank key的排名(从0开始，即小于key的元素数量)，key不存在时ok为false
+func (s *SkipList[K, V]) Rank(key K) (rank int, ok bool) {
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.level[i].next != nil && x.level[i].next.key <= key {
+			rank += x.level[i].span
+			x = x.level[i].next
+		}
+		if x != s.head && x.key == key {
+			return rank - 1, true
+		}
+	}
+	return 0, false
+}
+
+// ByRank 排名为rank(从0开始)的元素，越界时ok为false
+func (s *SkipList[K, V]) ByRank(rank int) (key K, val V, ok bool) {
+	if rank < 0 || rank >= s.size {
+		return key, val, false
+	}
+	return entry(s.byRank(rank + 1))
+}
+
+// All 按key升序遍历全部元素
+func (s *SkipList[K, V]) All() iter.Seq2[K, V] {
+	return ascend(func() *skipNode[K, V] { return s.head.level[0].next }, func(K) bool { return true })
+}
+
+// Ascend 按key升序遍历[from, to]之间的元素
+func (s *SkipList[K, V]) Ascend(from, to K) iter.Seq2[K, V] {
+	return ascend(func() *skipNode[K, V] { return s.ceiling(from) }, func(k K) bool { return k <= to })
+}
+
+// Descend 按key降序遍历[to, from]之间的元素，即从from开始向下遍历到to
+func (s *SkipList[K, V]) Descend(from, to K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for x := s.floor(from); x != nil && x.key >= to; x = x.prev {
+			if !yield(x.key, x.val) {
+				return
+			}
+		}
+	}
+}
+
+// Clear 清空跳表
+func (s *SkipList[K, V]) Clear() {
+	*s = *NewSkipList[K, V]()
+}
+
+// floor 小于等于key的最大节点
+func (s *SkipList[K, V]) floor(key K) *skipNode[K, V] {
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.level[i].next != nil && x.level[i].next.key <= key {
+			x = x.level[i].next
+		}
+	}
+	if x == s.head {
+		return nil
+	}
+	return x
+}
+
+// ceiling 大于等于key的最小节点
+func (s *SkipList[K, V]) ceiling(key K) *skipNode[K, V] {
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.level[i].next != nil && x.level[i].next.key < key {
+			x = x.level[i].next
+		}
+	}
+	return x.level[0].next
+}
+
+// byRank 排名为rank(从1开始)的节点
+func (s *SkipList[K, V]) byRank(rank int) *skipNode[K, V] {
+	x, traversed := s.head, 0
+	for i := s.level - 1; i >= 0; i-- {
+		for x.level[i].next != nil && traversed+x.level[i].span <= rank {
+			traversed += x.level[i].span
+			x = x.level[i].next
+		}
+		if traversed == rank {
+			return x
+		}
+	}
+	return nil
+}
+
+// ascend 从start返回的节点开始升序遍历，start在每次遍历时调用，迭代器创建后跳表的修改同样可见
+func ascend[K cmp.Ordered, V any](start func() *skipNode[K, V], in func(K) bool) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for x := start(); x != nil && in(x.key); x = x.level[0].next {
+			if !yield(x.key, x.val) {
+				return
+			}
+		}
+	}
+}
+
+func entry[K cmp.Ordered, V any](n *skipNode[K, V]) (key K, val V, ok bool) {
+	if n == nil {
+		return key, val, false
+	}
+	return n.key, n.val, true
+}
+
+// randomLevel 随机生成新节点的层数
+func randomLevel() int {
+	lvl := 1
+	for lvl < skipListMaxLevel && rand.IntN(skipListP) == 0 {
+		lvl++
+	}
+	return lvl
+}
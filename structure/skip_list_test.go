@@ -0,0 +1,132 @@
+package structure
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func Test_SkipList(t *testing.T) {
+	s := NewSkipList[int, string]()
+	vals := make(map[int]string)
+	for i := 0; i < 2000; i++ {
+		key := rand.Intn(1000)
+		if rand.Intn(3) == 0 {
+			_, ok := s.Delete(key)
+			if _, exist := vals[key]; ok != exist {
+				t.Fatalf("delete %d: expect %v got %v", key, exist, ok)
+			}
+			delete(vals, key)
+			continue
+		}
+		_, exist := vals[key]
+		vals[key] = string(rune('a' + i%26))
+		if replaced := s.Set(key, vals[key]); replaced != exist {
+			t.Fatalf("set %d: expect replaced %v got %v", key, exist, replaced)
+		}
+	}
+	keys := make([]int, 0, len(vals))
+	for k := range vals {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	if s.Len() != len(keys) {
+		t.Fatalf("expect len %d got %d", len(keys), s.Len())
+	}
+	for i, k := range keys {
+		if v, ok := s.Get(k); !ok || v != vals[k] {
+			t.Fatalf("get %d: expect %s got %s", k, vals[k], v)
+		}
+		if rank, ok := s.Rank(k); !ok || rank != i {
+			t.Fatalf("rank %d: expect %d got %d", k, i, rank)
+		}
+		if key, _, ok := s.ByRank(i); !ok || key != k {
+			t.Fatalf("by rank %d: expect %d got %d", i, k, key)
+		}
+	}
+	var all []int
+	for k := range s.All() {
+		all = append(all, k)
+	}
+	if !slices.Equal(all, keys) {
+		t.Fatal("all keys not in order")
+	}
+
+	// Floor/Ceiling 与排序数组上的二分查找结果一致
+	for q := -1; q <= 1001; q++ {
+		i, found := slices.BinarySearch(keys, q)
+		fk, _, fok := s.Floor(q)
+		ck, _, cok := s.Ceiling(q)
+		if found {
+			if fk != q || ck != q {
+				t.Fatalf("floor/ceiling %d got %d %d", q, fk, ck)
+			}
+			continue
+		}
+		if fok != (i > 0) || (fok && fk != keys[i-1]) || cok != (i < len(keys)) || (cok && ck != keys[i]) {
+			t.Fatalf("floor/ceiling %d got %d(%v) %d(%v)", q, fk, fok, ck, cok)
+		}
+		if _, ok := s.Rank(q); ok {
+			t.Fatalf("expect no rank for missing %d", q)
+		}
+	}
+
+	var asc, desc, expect []int
+	for _, k := range keys {
+		if k >= 200 && k <= 500 {
+			expect = append(expect, k)
+		}
+	}
+	for k := range s.Ascend(200, 500) {
+		asc = append(asc, k)
+	}
+	for k := range s.Descend(500, 200) {
+		desc = append(desc, k)
+	}
+	if !slices.Equal(asc, expect) {
+		t.Fatalf("unexpected ascend %v", asc)
+	}
+	slices.Reverse(desc)
+	if !slices.Equal(desc, expect) {
+		t.Fatalf("unexpected descend %v", desc)
+	}
+
+	minKey, _, _ := s.Min()
+	maxKey, _, _ := s.Max()
+	if minKey != keys[0] || maxKey != keys[len(keys)-1] {
+		t.Fatalf("unexpected min %d max %d", minKey, maxKey)
+	}
+	for _, k := range keys {
+		s.Delete(k)
+	}
+	if _, _, ok := s.Max(); ok || s.Len() != 0 {
+		t.Fatal("expect empty skip list")
+	}
+}
+
+func Test_SkipListIterReuse(t *testing.T) {
+	s := NewSkipList[int, string]()
+	// 迭代器创建后的修改在遍历时可见
+	all, asc := s.All(), s.Ascend(1, 3)
+	for i := 0; i <= 5; i++ {
+		s.Set(i, "")
+	}
+	s.Delete(0)
+	// 同一个迭代器可以多次遍历
+	for round := 0; round < 2; round++ {
+		var got []int
+		for k := range all {
+			got = append(got, k)
+		}
+		if !slices.Equal(got, []int{1, 2, 3, 4, 5}) {
+			t.Fatalf("round %d: unexpected all %v", round, got)
+		}
+		got = got[:0]
+		for k := range asc {
+			got = append(got, k)
+		}
+		if !slices.Equal(got, []int{1, 2, 3}) {
+			t.Fatalf("round %d: unexpected ascend %v", round, got)
+		}
+	}
+}
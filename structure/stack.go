@@ -3,6 +3,7 @@ package structure
 import (
 	"container/list"
 	"errors"
+	"iter"
 )
 
 type Stack[T any] struct {
@@ -16,7 +17,7 @@ func NewStack[T any]() *Stack[T] {
 }
 
 func (s *Stack[T]) Len() int {
-	return s.Len()
+	return s.List.Len()
 }
 
 func (s *Stack[T]) Push(v T) {
@@ -30,3 +31,52 @@ func (s *Stack[T]) Pop() (v T, err error) {
 	}
 	return v, errors.New("cannot pop in empty stack")
 }
+
+// Peek 返回栈顶元素但不出栈，栈为空时ok为false
+func (s *Stack[T]) Peek() (v T, ok bool) {
+	if backItem := s.Back(); backItem != nil {
+		return backItem.Value.(T), true
+	}
+	return v, false
+}
+
+// IsEmpty 栈是否为空
+func (s *Stack[T]) IsEmpty() bool {
+	return s.List.Len() == 0
+}
+
+// Clear 清空栈
+func (s *Stack[T]) Clear() {
+	s.Init()
+}
+
+// Clone 拷贝栈(浅拷贝元素)
+func (s *Stack[T]) Clone() *Stack[T] {
+	cp := NewStack[T]()
+	for e := s.Front(); e != nil; e = e.Next() {
+		cp.PushBack(e.Value)
+	}
+	return cp
+}
+
+// All 由栈顶到栈底遍历全部元素，不修改栈
+func (s *Stack[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for e := s.Back(); e != nil; e = e.Prev() {
+			if !yield(e.Value.(T)) {
+				return
+			}
+		}
+	}
+}
+
+// Drain 由栈顶到栈底依次出栈，遍历中途停止时未出栈的元素保留在栈中
+func (s *Stack[T]) Drain() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for backItem := s.Back(); backItem != nil; backItem = s.Back() {
+			if !yield(s.Remove(backItem).(T)) {
+				return
+			}
+		}
+	}
+}
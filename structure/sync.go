@@ -0,0 +1,241 @@
+package structure
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// waitList 等待新元素的通知，有等待者时才创建通道，由调用方加锁保护
+type waitList struct {
+	notify chan struct{}
+}
+
+// wait 返回新元素加入时会被关闭的通道
+func (w *waitList) wait() <-chan struct{} {
+	if w.notify == nil {
+		w.notify = make(chan struct{})
+	}
+	return w.notify
+}
+
+// wake 唤醒全部等待者
+func (w *waitList) wake() {
+	if w.notify != nil {
+		close(w.notify)
+		w.notify = nil
+	}
+}
+
+// SyncHeap 并发安全的堆
+type SyncHeap[T comparable] struct {
+	heap    *HeapArea[T]
+	lock    sync.Mutex
+	waiters waitList
+}
+
+// NewSyncHeap 初始化并发安全的堆，参数同 NewHeapArea
+func NewSyncHeap[T comparable](t bool, less func(data []T, i, j int) bool) *SyncHeap[T] {
+	return &SyncHeap[T]{heap: NewHeapArea[T](t, less)}
+}
+
+// Push 新增元素，并唤醒阻塞中的 PopWait
+func (s *SyncHeap[T]) Push(val T) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.heap.Push(val)
+	s.waiters.wake()
+}
+
+// Pop 弹出堆顶元素，堆为空时ok为false
+func (s *SyncHeap[T]) Pop() (val T, ok bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.heap.IsEmpty() {
+		return val, false
+	}
+	return s.heap.Pop(), true
+}
+
+// PopWait 弹出堆顶元素，堆为空时阻塞直到有新元素或ctx结束(返回ctx.Err())
+func (s *SyncHeap[T]) PopWait(ctx context.Context) (val T, err error) {
+	for {
+		s.lock.Lock()
+		if !s.heap.IsEmpty() {
+			val = s.heap.Pop()
+			s.lock.Unlock()
+			return val, nil
+		}
+		notify := s.waiters.wait()
+		s.lock.Unlock()
+		select {
+		case <-ctx.Done():
+			return val, ctx.Err()
+		case <-notify:
+		}
+	}
+}
+
+// Peek 返回堆顶元素但不弹出，堆为空时ok为false
+func (s *SyncHeap[T]) Peek() (val T, ok bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.heap.IsEmpty() {
+		return val, false
+	}
+	return s.heap.Peek(), true
+}
+
+// Len 元素数量
+func (s *SyncHeap[T]) Len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.heap.Len()
+}
+
+// IsEmpty 堆是否为空
+func (s *SyncHeap[T]) IsEmpty() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.heap.IsEmpty()
+}
+
+// Clear 清空堆
+func (s *SyncHeap[T]) Clear() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.heap.Clear()
+}
+
+// Clone 拷贝堆(浅拷贝元素)
+func (s *SyncHeap[T]) Clone() *SyncHeap[T] {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return &SyncHeap[T]{heap: s.heap.Clone()}
+}
+
+// All 按出堆顺序遍历调用时刻的快照，不修改堆，遍历期间不持有锁
+func (s *SyncHeap[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.lock.Lock()
+		snapshot := s.heap.Clone()
+		s.lock.Unlock()
+		snapshot.Drain()(yield)
+	}
+}
+
+// Drain 按出堆顺序依次弹出元素直到堆为空，不会阻塞等待新元素
+func (s *SyncHeap[T]) Drain() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			val, ok := s.Pop()
+			if !ok || !yield(val) {
+				return
+			}
+		}
+	}
+}
+
+// SyncStack 并发安全的栈
+type SyncStack[T any] struct {
+	stack   *Stack[T]
+	lock    sync.Mutex
+	waiters waitList
+}
+
+// NewSyncStack 初始化并发安全的栈
+func NewSyncStack[T any]() *SyncStack[T] {
+	return &SyncStack[T]{stack: NewStack[T]()}
+}
+
+// Push 入栈，并唤醒阻塞中的 PopWait
+func (s *SyncStack[T]) Push(v T) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.stack.Push(v)
+	s.waiters.wake()
+}
+
+// Pop 出栈，栈为空时ok为false
+func (s *SyncStack[T]) Pop() (v T, ok bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	v, err := s.stack.Pop()
+	return v, err == nil
+}
+
+// PopWait 出栈，栈为空时阻塞直到有新元素或ctx结束(返回ctx.Err())
+func (s *SyncStack[T]) PopWait(ctx context.Context) (v T, err error) {
+	for {
+		s.lock.Lock()
+		if !s.stack.IsEmpty() {
+			v, err = s.stack.Pop()
+			s.lock.Unlock()
+			return v, err
+		}
+		notify := s.waiters.wait()
+		s.lock.Unlock()
+		select {
+		case <-ctx.Done():
+			return v, ctx.Err()
+		case <-notify:
+		}
+	}
+}
+
+// Peek 返回栈顶元素但不出栈，栈为空时ok为false
+func (s *SyncStack[T]) Peek() (v T, ok bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.stack.Peek()
+}
+
+// Len 元素数量
+func (s *SyncStack[T]) Len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.stack.Len()
+}
+
+// IsEmpty 栈是否为空
+func (s *SyncStack[T]) IsEmpty() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.stack.IsEmpty()
+}
+
+// Clear 清空栈
+func (s *SyncStack[T]) Clear() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.stack.Clear()
+}
+
+// Clone 拷贝栈(浅拷贝元素)
+func (s *SyncStack[T]) Clone() *SyncStack[T] {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return &SyncStack[T]{stack: s.stack.Clone()}
+}
+
+// All 由栈顶到栈底遍历调用时刻的快照，不修改栈，遍历期间不持有锁
+func (s *SyncStack[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.lock.Lock()
+		snapshot := s.stack.Clone()
+		s.lock.Unlock()
+		snapshot.All()(yield)
+	}
+}
+
+// Drain 依次出栈直到栈为空，不会阻塞等待新元素
+func (s *SyncStack[T]) Drain() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			v, ok := s.Pop()
+			if !ok || !yield(v) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package structure
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_Stack(t *testing.T) {
+	s := NewStack[int]()
+	for i := 1; i <= 3; i++ {
+		s.Push(i)
+	}
+	cp := s.Clone()
+	if s.Len() != 3 || s.IsEmpty() {
+		t.Fatalf("expect len 3 got %d", s.Len())
+	}
+	if got := slices.Collect(s.All()); !slices.Equal(got, []int{3, 2, 1}) || s.Len() != 3 {
+		t.Fatalf("unexpected all %v", got)
+	}
+	for v := range s.Drain() {
+		if v == 2 {
+			break
+		}
+	}
+	if v, ok := s.Peek(); !ok || v != 1 || s.Len() != 1 {
+		t.Fatalf("expect 1 left got %v %d", v, s.Len())
+	}
+	s.Clear()
+	if _, err := s.Pop(); err == nil || !s.IsEmpty() || cp.Len() != 3 {
+		t.Fatal("expect empty stack and untouched clone")
+	}
+}
+
+func Test_SyncHeap(t *testing.T) {
+	h := NewSyncHeap[int](false, func(data []int, i, j int) bool { return data[i] < data[j] })
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := h.PopWait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect deadline exceeded got %v", err)
+	}
+
+	// 多个消费者阻塞等待，生产者并发写入，每个元素恰好被消费一次
+	var (
+		wg   sync.WaitGroup
+		lock sync.Mutex
+		got  []int
+	)
+	consumeCtx, stop := context.WithCancel(context.Background())
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				v, err := h.PopWait(consumeCtx)
+				if err != nil {
+					return
+				}
+				lock.Lock()
+				got = append(got, v)
+				lock.Unlock()
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		h.Push(i)
+	}
+	for deadline := time.Now().Add(time.Second); h.Len() > 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	stop()
+	wg.Wait()
+	slices.Sort(got)
+	if len(got) != 100 || got[0] != 0 || got[99] != 99 {
+		t.Fatalf("unexpected consumed %d items", len(got))
+	}
+
+	for _, v := range []int{5, 3, 8, 1} {
+		h.Push(v)
+	}
+	cp := h.Clone()
+	if all := slices.Collect(h.All()); !slices.Equal(all, []int{1, 3, 5, 8}) || h.Len() != 4 {
+		t.Fatalf("unexpected all %v", all)
+	}
+	if drained := slices.Collect(h.Drain()); !slices.Equal(drained, []int{1, 3, 5, 8}) || !h.IsEmpty() {
+		t.Fatalf("unexpected drain %v", drained)
+	}
+	cp.Clear()
+	if cp.Len() != 0 {
+		t.Fatal("expect cleared clone")
+	}
+}
+
+func Test_SyncStack(t *testing.T) {
+	s := NewSyncStack[string]()
+	done := make(chan string)
+	go func() {
+		v, _ := s.PopWait(context.Background())
+		done <- v
+	}()
+	time.Sleep(20 * time.Millisecond)
+	s.Push("a")
+	if v := <-done; v != "a" {
+		t.Fatalf("expect a got %s", v)
+	}
+	s.Push("b")
+	s.Push("c")
+	if v, ok := s.Peek(); !ok || v != "c" {
+		t.Fatalf("expect c got %s", v)
+	}
+	if all := slices.Collect(s.Clone().All()); !slices.Equal(all, []string{"c", "b"}) {
+		t.Fatalf("unexpected all %v", all)
+	}
+	if drained := slices.Collect(s.Drain()); !slices.Equal(drained, []string{"c", "b"}) || !s.IsEmpty() {
+		t.Fatalf("unexpected drain %v", drained)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.PopWait(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expect canceled got %v", err)
+	}
+}